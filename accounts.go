@@ -0,0 +1,427 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	lib "github.com/charles-m-knox/finance-planner-lib"
+)
+
+// This file contains the logic for accounts, which split a profile's balance
+// between e.g. a checking account, a savings account and a credit card. The
+// profile's own starting balance and any transactions without an account
+// belong to its main account, which is what every profile without accounts
+// has always had. Transfers move money from one account to another, and do
+// not change the total balance, unless they are into or out of an investment
+// account, which is not cash.
+
+// The separator between the accounts of a profile when they are edited as
+// text.
+const AccountsSeparator = ";"
+
+// Account is a named account within a profile, which has its own balance in
+// the results.
+type Account struct {
+	Name            string `yaml:"name"`
+	StartingBalance string `yaml:"startingBalance"`
+	// empty for a regular account, AccountTypeCreditCard or
+	// AccountTypeInvestment
+	Type string `yaml:"type,omitempty"`
+	// for credit cards, the day of the month that statements close on
+	StatementDay int `yaml:"statementDay,omitempty"`
+	// for credit cards, the number of days after a statement closes that its
+	// payment is due; see DefaultCreditCardDueDays
+	DueDays int `yaml:"dueDays,omitempty"`
+	// for credit cards, the account that statements are paid from; empty
+	// for the main account
+	FundingAccount string `yaml:"fundingAccount,omitempty"`
+	// for investment accounts, the expected annual return, as a percentage
+	Return float64 `yaml:"return,omitempty"`
+}
+
+// AccountBalances are the daily balances of each of a profile's accounts, or
+// of each of its loans. They are only set if the profile has any.
+type AccountBalances struct {
+	// the names of the accounts, starting with the main account, or of the
+	// loans
+	Names []string
+	// Balances[i][j] is the balance of the j'th account at the end of the
+	// i'th day of the results
+	Balances [][]int
+}
+
+// GetBalances returns the balance of each account at the end of the i'th day
+// of the results, or nil if the profile has no accounts.
+func (a *AccountBalances) GetBalances(i int) []int {
+	if i < 0 || i >= len(a.Balances) {
+		return nil
+	}
+
+	return a.Balances[i]
+}
+
+// getResultsBalanceHeader returns the name of the results' balance column,
+// which is the total of all accounts if the profile has any.
+func getResultsBalanceHeader(accounts AccountBalances) string {
+	if len(accounts.Names) > 0 {
+		return FP.T["ResultsColumnTotalBalance"]
+	}
+
+	return FP.T["ResultsColumnBalance"]
+}
+
+// ResultsExtra holds the parts of a profile's results that the library's
+// results have no room for.
+type ResultsExtra struct {
+	Interest InterestTotals
+	Accounts AccountBalances
+	// the remaining principal of each loan, as a negative balance
+	Loans AccountBalances
+	// the net worth at the end of each day; see getNetWorth
+	NetWorth []int
+	// the Monte Carlo projection on each day, if the profile has simulations;
+	// see runMonteCarlo
+	MonteCarlo []MonteCarloDay
+	// the notable days of the results, such as the first negative day
+	Alerts BalanceAlerts
+	// the progress of each of the profile's goals
+	Goals []GoalProgress
+}
+
+// GetNetWorth returns the net worth at the end of the i'th day of the
+// results, and false if the profile has no net worth column.
+func (e *ResultsExtra) GetNetWorth(i int) (int, bool) {
+	if i < 0 || i >= len(e.NetWorth) {
+		return 0, false
+	}
+
+	return e.NetWorth[i], true
+}
+
+// GetMonteCarlo returns the Monte Carlo projection at the end of the i'th day
+// of the results, and false if the profile has no Monte Carlo columns.
+func (e *ResultsExtra) GetMonteCarlo(i int) (MonteCarloDay, bool) {
+	if i < 0 || i >= len(e.MonteCarlo) {
+		return MonteCarloDay{}, false
+	}
+
+	return e.MonteCarlo[i], true
+}
+
+// hasAccounts returns true if the profile's balance is split between
+// accounts.
+func hasAccounts(p *Profile) bool {
+	return len(p.Accounts) > 0
+}
+
+// isTransfer returns true if the transaction moves money between accounts
+// instead of earning or spending it.
+func isTransfer(tx *TX) bool {
+	return tx.TransferTo != ""
+}
+
+// getAccountName returns the name of an account as it is shown to the user.
+// The main account has no name of its own.
+func getAccountName(account string) string {
+	if account == "" {
+		return FP.T["AccountMainName"]
+	}
+
+	return account
+}
+
+// getAccountNames returns the names of the profile's accounts, including the
+// main account, which comes first and is named "".
+func getAccountNames(p *Profile) []string {
+	names := []string{""}
+
+	for i := range p.Accounts {
+		names = append(names, p.Accounts[i].Name)
+	}
+
+	return names
+}
+
+// validateAccount returns an error if the account is not one of the profile's
+// accounts. The main account ("") is always valid.
+func validateAccount(p *Profile, account string) error {
+	if account == "" || slices.ContainsFunc(p.Accounts, func(a Account) bool { return a.Name == account }) {
+		return nil
+	}
+
+	return fmt.Errorf("%v: %v", FP.T["AccountUnknown"], account)
+}
+
+// getTransferName returns the name of a transfer as it is shown in the
+// results, such as "rainy day fund (Main → savings)".
+func getTransferName(tx *TX) string {
+	return fmt.Sprintf("%v (%v → %v)", tx.Name, getAccountName(tx.Account), getAccountName(tx.TransferTo))
+}
+
+// withAmountSign returns a copy of the transaction whose amount (and amount
+// changes) are all positive, if sign is 1, or negative, if sign is -1. This
+// is how a transfer affects the accounts on either side of it.
+func withAmountSign(tx TX, sign int) TX {
+	abs := func(a int) int { return max(a, -a) }
+
+	tx.Amount = sign * abs(tx.Amount)
+	tx.AmountChanges = slices.Clone(tx.AmountChanges)
+
+	for i := range tx.AmountChanges {
+		tx.AmountChanges[i].Amount = sign * abs(tx.AmountChanges[i].Amount)
+	}
+
+	return tx
+}
+
+// getTotalTransactions returns the transactions that affect the total balance
+// of the profile, which is the balance of its cash accounts. Transfers and
+// the transactions of investment accounts are kept, so that they are still
+// listed in the results, but their amount is 0, unless they move money into
+// or out of the cash accounts.
+func getTotalTransactions(p *Profile, txs []TX) []TX {
+	total := make([]TX, 0, len(txs))
+
+	for i := range txs {
+		tx := txs[i]
+		from := isCash(p, tx.Account)
+
+		switch {
+		case isTransfer(&tx) && from && !isCash(p, tx.TransferTo):
+			tx = withAmountSign(tx, -1)
+		case isTransfer(&tx) && !from && isCash(p, tx.TransferTo):
+			tx = withAmountSign(tx, 1)
+		case isTransfer(&tx), !from:
+			tx.Amount = 0
+			tx.AmountChanges = nil
+		}
+
+		if isTransfer(&tx) {
+			tx.Name = getTransferName(&tx)
+		}
+
+		total = append(total, tx)
+	}
+
+	return total
+}
+
+// getAccountTransactions returns the transactions that affect the given
+// account. Transfers out of the account are expenses, and transfers into it
+// are income.
+func getAccountTransactions(txs []TX, account string) []TX {
+	result := []TX{}
+
+	for i := range txs {
+		tx := &txs[i]
+
+		switch {
+		case !isTransfer(tx) && tx.Account == account:
+			result = append(result, *tx)
+		case isTransfer(tx) && tx.Account == account:
+			result = append(result, withAmountSign(*tx, -1))
+		case isTransfer(tx) && tx.TransferTo == account:
+			result = append(result, withAmountSign(*tx, 1))
+		}
+	}
+
+	return result
+}
+
+// getAccountStartingBalance returns the starting balance of the account. The
+// main account's starting balance is the profile's.
+func getAccountStartingBalance(p *Profile, account string) int {
+	if account == "" {
+		return int(lib.ParseDollarAmount(p.StartingBalance, true))
+	}
+
+	for i := range p.Accounts {
+		if p.Accounts[i].Name == account {
+			return int(lib.ParseDollarAmount(p.Accounts[i].StartingBalance, true))
+		}
+	}
+
+	return 0
+}
+
+// getTotalStartingBalance returns the sum of the starting balances of the
+// profile's cash accounts, which excludes investment accounts.
+func getTotalStartingBalance(p *Profile) int {
+	total := 0
+
+	for _, account := range getAccountNames(p) {
+		if isCash(p, account) {
+			total += getAccountStartingBalance(p, account)
+		}
+	}
+
+	return total
+}
+
+// validateTransactionAccounts returns an error if any active transaction
+// refers to an account that the profile doesn't have, or transfers to the
+// same account that it transfers from.
+func validateTransactionAccounts(p *Profile) error {
+	for i := range p.TX {
+		tx := &p.TX[i]
+		if !tx.Active {
+			continue
+		}
+
+		for _, account := range []string{tx.Account, tx.TransferTo} {
+			err := validateAccount(p, account)
+			if err != nil {
+				return fmt.Errorf("%v: %w", tx.Name, err)
+			}
+		}
+
+		if isTransfer(tx) && tx.Account == tx.TransferTo {
+			return fmt.Errorf("%v: %v", tx.Name, FP.T["AccountTransferToSelf"])
+		}
+	}
+
+	return nil
+}
+
+// applyAccounts calculates the daily balance of each of the profile's
+// accounts from the transactions, including any interest that each account
+// earns or is charged, which is also added to the results. The results must
+// have been calculated from getTotalTransactions (of the same transactions)
+// and getTotalStartingBalance. Credit cards are paid in full every statement,
+// so they are never charged interest. Investment accounts grow at their
+// expected return instead, which is not added to the results, since they are
+// not part of the balance.
+func applyAccounts(
+	results []lib.Result,
+	p *Profile,
+	transactions []TX,
+	startDate, endDate time.Time,
+	holidays HolidayCalendar,
+) (ResultsExtra, error) {
+	extra := ResultsExtra{}
+	names := getAccountNames(p)
+
+	extra.Accounts.Names = names
+	extra.Accounts.Balances = make([][]int, len(results))
+
+	for i := range extra.Accounts.Balances {
+		extra.Accounts.Balances[i] = make([]int, len(names))
+	}
+
+	for j, account := range names {
+		txs, err := prepareTransactions(getAccountTransactions(transactions, account), startDate, endDate, holidays)
+		if err != nil {
+			return extra, err
+		}
+
+		bal := getAccountStartingBalance(p, account)
+
+		accountResults, err := lib.GetResults(txs, startDate, endDate, bal, func(_ string) {})
+		if err != nil {
+			return extra, err
+		}
+
+		interest := make([]int, len(accountResults))
+
+		switch investment := getInvestment(p, account); {
+		case investment != nil:
+			interest = getInvestmentGrowth(accountResults, bal, investment)
+		case !isCreditCardName(p, account):
+			interest = getDailyInterest(accountResults, bal, p)
+
+			label := fmt.Sprintf("%v, %v", FP.T["ResultsInterestLine"], getAccountName(account))
+			totals := addInterest(results, interest, label)
+
+			extra.Interest.Earned += totals.Earned
+			extra.Interest.Charged += totals.Charged
+		}
+
+		added := 0
+
+		for i := range accountResults {
+			added += interest[i]
+			extra.Accounts.Balances[i][j] = accountResults[i].Balance + added
+		}
+	}
+
+	return extra, nil
+}
+
+// formatAccounts formats the profile's accounts as text, such as
+// "savings $5,000.00; credit card $-250.00". It can be parsed with
+// parseAccounts.
+func formatAccounts(accounts []Account) string {
+	entries := make([]string, 0, len(accounts))
+
+	for i := range accounts {
+		entry := fmt.Sprintf("%v %v", accounts[i].Name, accounts[i].StartingBalance)
+		switch {
+		case isCreditCard(&accounts[i]):
+			entry = fmt.Sprintf("%v %v", entry, formatCreditCard(&accounts[i]))
+		case isInvestment(&accounts[i]):
+			entry = fmt.Sprintf("%v %v", entry, formatInvestment(&accounts[i]))
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return strings.Join(entries, fmt.Sprintf("%v ", AccountsSeparator))
+}
+
+// parseAccounts parses accounts that were formatted with formatAccounts. Each
+// entry is the name of an account, optionally followed by its starting
+// balance, which is 0 if omitted, and then by its credit card settings, if it
+// is a credit card (see parseCreditCard), or its expected annual return, if
+// it is an investment account (see parseInvestment). The starting balance
+// must start with a $ or a sign, so that names ending in a number, such as
+// "savings 2025", aren't mistaken for a name and a balance. Account names
+// must be unique.
+func parseAccounts(s string) ([]Account, error) {
+	accounts := []Account{}
+
+	for _, entry := range strings.Split(s, AccountsSeparator) {
+		entry, card, isCard := strings.Cut(entry, fmt.Sprintf(" %v", CreditCardPrefix))
+		entry, investment, isInvestment := strings.Cut(entry, fmt.Sprintf(" %v", InvestmentPrefix))
+
+		fields := strings.Fields(entry)
+		if len(fields) == 0 {
+			continue
+		}
+
+		balance := 0
+		last := fields[len(fields)-1]
+
+		if len(fields) > 1 && strings.ContainsAny(last[:1], "$+-") && strings.ContainsAny(last, "0123456789") {
+			balance = int(lib.ParseDollarAmount(last, true))
+			fields = fields[:len(fields)-1]
+		}
+
+		name := strings.Join(fields, " ")
+		if slices.ContainsFunc(accounts, func(a Account) bool { return a.Name == name }) {
+			return nil, fmt.Errorf("%v: %v", FP.T["AccountDuplicate"], name)
+		}
+
+		a := Account{Name: name, StartingBalance: lib.FormatAsCurrency(balance)}
+
+		var err error
+
+		switch {
+		case isCard && isInvestment:
+			err = fmt.Errorf("%v", FP.T["AccountMultipleTypes"])
+		case isCard:
+			err = parseCreditCard(card, &a)
+		case isInvestment:
+			err = parseInvestment(investment, &a)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("%v: %w", name, err)
+		}
+
+		accounts = append(accounts, a)
+	}
+
+	return accounts, nil
+}
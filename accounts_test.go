@@ -0,0 +1,80 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAccounts(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    []Account
+		wantErr bool
+	}{
+		{
+			name: "empty",
+			s:    "",
+			want: []Account{},
+		},
+		{
+			name: "names and balances",
+			s:    "savings $5,000.00; credit card $-250.00",
+			want: []Account{
+				{Name: "savings", StartingBalance: "$5000.00"},
+				{Name: "credit card", StartingBalance: "$-250.00"},
+			},
+		},
+		{
+			name: "balance omitted",
+			s:    "savings",
+			want: []Account{{Name: "savings", StartingBalance: "$0.00"}},
+		},
+		{
+			name: "signed balances",
+			s:    "savings +5000; loan -250.25",
+			want: []Account{
+				{Name: "savings", StartingBalance: "$5000.00"},
+				{Name: "loan", StartingBalance: "$-250.25"},
+			},
+		},
+		{
+			name: "name ending in a number",
+			s:    "savings 2025; my 401k $100.00",
+			want: []Account{
+				{Name: "savings 2025", StartingBalance: "$0.00"},
+				{Name: "my 401k", StartingBalance: "$100.00"},
+			},
+		},
+		{
+			name:    "duplicate names",
+			s:       "savings $1.00; savings $2.00",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseAccounts(tt.s)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got no error, want one")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("failed to parse: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+
+			if again, err := parseAccounts(formatAccounts(got)); err != nil || !reflect.DeepEqual(again, got) {
+				t.Errorf("got %+v after formatting and parsing again, want %+v", again, got)
+			}
+		})
+	}
+}
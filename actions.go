@@ -2,16 +2,12 @@ package main
 
 import (
 	"fmt"
-	"os"
 	"slices"
 	"strings"
 	"time"
 
-	lib "github.com/charles-m-knox/finance-planner-lib"
-
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
-	"gopkg.in/yaml.v3"
 )
 
 func actionRedo(e *tcell.EventKey) *tcell.EventKey {
@@ -21,7 +17,7 @@ func actionRedo(e *tcell.EventKey) *tcell.EventKey {
 		switch FP.App.GetFocus() {
 		case FP.TransactionsInputField:
 			return e
-		case FP.TransactionsTable:
+		case FP.TransactionsTable, FP.GoalsTable:
 			redo()
 
 			return nil
@@ -42,7 +38,7 @@ func actionUndo(e *tcell.EventKey) *tcell.EventKey {
 		switch FP.App.GetFocus() {
 		case FP.TransactionsInputField:
 			return e
-		case FP.TransactionsTable:
+		case FP.TransactionsTable, FP.GoalsTable:
 			undo()
 
 			return nil
@@ -56,7 +52,13 @@ func actionUndo(e *tcell.EventKey) *tcell.EventKey {
 	}
 }
 
-func actionQuit() *tcell.EventKey {
+func actionQuit(e *tcell.EventKey) *tcell.EventKey {
+	// allow typing characters that are bound to quit, such as q, into any
+	// input field
+	if _, ok := FP.App.GetFocus().(*tview.InputField); ok && e.Key() == tcell.KeyRune {
+		return e
+	}
+
 	promptExit()
 
 	return nil
@@ -109,8 +111,8 @@ func actionMove(e *tcell.EventKey) *tcell.EventKey {
 
 			// first delete the values from the slice and keep track of
 			// them
-			deleted := []lib.TX{}
-			newTX := []lib.TX{}
+			deleted := []TX{}
+			newTX := []TX{}
 
 			for i := range FP.SelectedProfile.TX {
 				//nolint:gocritic
@@ -269,6 +271,8 @@ func actionDelete(e *tcell.EventKey) *tcell.EventKey {
 			getTransactionsTable()
 			FP.TransactionsTable.Select(cr, cc)
 			FP.App.SetFocus(FP.TransactionsTable)
+		case FP.GoalsTable:
+			deleteGoal()
 		case FP.ProfileList:
 			if len(FP.Config.Profiles) <= 1 {
 				FP.ProfileStatusText.SetText("[gray] can't delete last profile")
@@ -345,6 +349,32 @@ func actionDelete(e *tcell.EventKey) *tcell.EventKey {
 	}
 }
 
+// insertTransactions inserts new transactions into the selected profile at
+// the transactions table's selected row (cr), and then re-selects that cell.
+func insertTransactions(nt []TX, cr, cc int) {
+	if len(nt) == 0 {
+		return
+	}
+
+	// handles the case of adding/duplicating when the cursor is on the
+	// headers row
+	actual := cr - 1 // skip header
+	if actual < 0 {
+		actual = 0
+	}
+
+	if len(FP.SelectedProfile.TX) == 0 || actual > len(FP.SelectedProfile.TX)-1 {
+		FP.SelectedProfile.TX = append(FP.SelectedProfile.TX, nt...)
+	} else {
+		FP.SelectedProfile.TX = slices.Insert(FP.SelectedProfile.TX, actual, nt...)
+	}
+
+	modified()
+	getTransactionsTable()
+	FP.TransactionsTable.Select(cr, cc)
+	FP.App.SetFocus(FP.TransactionsTable)
+}
+
 func actionAdd(e *tcell.EventKey, duplicating bool) *tcell.EventKey {
 	pageName, _ := FP.Pages.GetFrontPage()
 	switch pageName {
@@ -355,15 +385,24 @@ func actionAdd(e *tcell.EventKey, duplicating bool) *tcell.EventKey {
 		case FP.TransactionsTable:
 			cr, cc := FP.TransactionsTable.GetSelection()
 			actual := cr - 1 // skip header
-			nt := []lib.TX{}
+			nt := []TX{}
 
 			FP.LastSelection = -1
 
+			if !duplicating && !FP.Config.DisableTransactionTemplates {
+				// the new transaction is inserted once a template is picked
+				promptTXTemplate(func(newTX TX) {
+					insertTransactions([]TX{newTX}, cr, cc)
+				})
+
+				return e
+			}
+
 			if !duplicating {
-				// largestOrderHolder := []lib.TX{}
+				// largestOrderHolder := []TX{}
 				// largestOrderHolder = append(largestOrderHolder, FP.SelectedProfile.TX...)
 				// largestOrderHolder = append(largestOrderHolder, nt...)
-				newTX := lib.GetNewTX(time.Now())
+				newTX := getNewTX(time.Now())
 				// newTX.Order = lib.GetLargestOrder(largestOrderHolder) + 1
 				nt = append(nt, newTX)
 			} else {
@@ -392,52 +431,24 @@ func actionAdd(e *tcell.EventKey, duplicating bool) *tcell.EventKey {
 					if isHighlightedRow || isSelectedDuplicationCandidate {
 						// keep track of the highest order in a temporary
 						// slice
-						// largestOrderHolder := []lib.TX{}
+						// largestOrderHolder := []TX{}
 						// largestOrderHolder = append(largestOrderHolder, FP.SelectedProfile.TX...)
 						// largestOrderHolder = append(largestOrderHolder, nt...)
-						newTX := lib.GetNewTX(now)
+						newTX := duplicateTX(&FP.SelectedProfile.TX[i], now)
 						// newTX.Order = lib.GetLargestOrder(largestOrderHolder) + 1
 
-						newTX.Amount = FP.SelectedProfile.TX[i].Amount
-						newTX.Active = FP.SelectedProfile.TX[i].Active
-						newTX.Name = FP.SelectedProfile.TX[i].Name
-						newTX.Note = FP.SelectedProfile.TX[i].Note
-						newTX.RRule = FP.SelectedProfile.TX[i].RRule
-						newTX.Frequency = FP.SelectedProfile.TX[i].Frequency
-						newTX.Interval = FP.SelectedProfile.TX[i].Interval
-						newTX.Weekdays = FP.SelectedProfile.TX[i].Weekdays
-						newTX.StartsDay = FP.SelectedProfile.TX[i].StartsDay
-						newTX.StartsMonth = FP.SelectedProfile.TX[i].StartsMonth
-						newTX.StartsYear = FP.SelectedProfile.TX[i].StartsYear
-						newTX.EndsDay = FP.SelectedProfile.TX[i].EndsDay
-						newTX.EndsMonth = FP.SelectedProfile.TX[i].EndsMonth
-						newTX.EndsYear = FP.SelectedProfile.TX[i].EndsYear
-
 						nt = append(nt, newTX)
 					}
 				}
 			}
 
-			if len(nt) > 0 {
-				// handles the case of adding/duplicating when the cursor
-				// is on the headers row
-				if actual < 0 {
-					actual = 0
-				}
-
-				if len(FP.SelectedProfile.TX) == 0 || actual > len(FP.SelectedProfile.TX)-1 {
-					FP.SelectedProfile.TX = append(FP.SelectedProfile.TX, nt...)
-				} else {
-					FP.SelectedProfile.TX = slices.Insert(FP.SelectedProfile.TX, actual, nt...)
-				}
-
-				modified()
-				getTransactionsTable()
-				FP.TransactionsTable.Select(cr, cc)
-				FP.App.SetFocus(FP.TransactionsTable)
-			}
+			insertTransactions(nt, cr, cc)
 
 			return e
+		case FP.GoalsTable:
+			addGoal()
+
+			return nil
 		case FP.ProfileList:
 			// add/duplicate new profile
 			FP.TransactionsInputField.SetDoneFunc(func(key tcell.Key) {
@@ -529,36 +540,70 @@ func actionEdit(e *tcell.EventKey) *tcell.EventKey {
 			})
 			activateTransactionsInputField(fmt.Sprintf("set new unique profile name for %v:", FP.SelectedProfile.Name), "")
 
+			return nil
+		case FP.TransactionsTable:
+			// edit the amount schedule when the amount column is selected
+			row, column := FP.TransactionsTable.GetSelection()
+			if row < 1 || row > len(FP.SelectedProfile.TX) ||
+				FP.TransactionsTableHeaders[column].Text != FP.T["TransactionsColumnAmount"] {
+				return e
+			}
+
+			txChangeAmountSchedule(row - 1)
+
+			return nil
+		case FP.GoalsTable:
+			goalsTableSelectedFunc(FP.GoalsTable.GetSelection())
+
 			return nil
 		default:
 			return e
 		}
 	case PageResults:
 		return e
+	case PageAmortization:
+		if FP.App.GetFocus() == FP.AmortizationInputField {
+			return e
+		}
+
+		editAmortizationExtraPrincipal()
+
+		return nil
 	default:
 		return e
 	}
 }
 
 func actionSave() *tcell.EventKey {
-	if FP.Config.Version == "" {
-		FP.Config.Version = ConfigVersion
+	if guardExternalConfigChange() {
+		return nil
 	}
 
-	b, err := yaml.Marshal(FP.Config)
+	err := saveConfig(FP.FlagConfigFile, &FP.Config)
 	if err != nil {
-		FP.ProfileStatusText.SetText("failed to marshal")
+		FP.ProfileStatusText.SetText(fmt.Sprintf("%v%v%v",
+			FP.Colors["ProfileStatusTextError"],
+			tview.Escape(err.Error()),
+			Reset,
+		))
+
 		return nil
 	}
 
-	err = os.WriteFile(FP.FlagConfigFile, b, os.FileMode(0o644))
+	markSaved()
+
+	err = saveUndoHistory()
 	if err != nil {
-		FP.ProfileStatusText.SetText("failed to save")
+		FP.ProfileStatusText.SetText(fmt.Sprintf("%v%v: %v%v",
+			FP.Colors["ProfileStatusTextError"],
+			FP.T["UndoHistorySaveFailed"],
+			err.Error(),
+			Reset,
+		))
+
 		return nil
 	}
 
-	FP.SelectedProfile.Modified = false
-
 	FP.ProfileStatusText.SetText("[gray] saved changes")
 
 	return nil
@@ -610,6 +655,18 @@ func actionHome(e *tcell.EventKey) *tcell.EventKey {
 func actionDown(e *tcell.EventKey) *tcell.EventKey {
 	switch FP.App.GetFocus() {
 	case FP.TransactionsInputField:
+		if FP.TransactionsSearch.Active {
+			nextSearchMatch(&FP.TransactionsSearch, FP.TransactionsTable, true)
+			setTransactionsSearchLabel()
+		}
+
+		return nil
+	case FP.ResultsInputField:
+		if FP.ResultsSearch.Active {
+			nextSearchMatch(&FP.ResultsSearch, FP.ResultsTable, true)
+			setResultsSearchLabel()
+		}
+
 		return nil
 	default:
 		return e
@@ -619,6 +676,18 @@ func actionDown(e *tcell.EventKey) *tcell.EventKey {
 func actionUp(e *tcell.EventKey) *tcell.EventKey {
 	switch FP.App.GetFocus() {
 	case FP.TransactionsInputField:
+		if FP.TransactionsSearch.Active {
+			nextSearchMatch(&FP.TransactionsSearch, FP.TransactionsTable, false)
+			setTransactionsSearchLabel()
+		}
+
+		return nil
+	case FP.ResultsInputField:
+		if FP.ResultsSearch.Active {
+			nextSearchMatch(&FP.ResultsSearch, FP.ResultsTable, false)
+			setResultsSearchLabel()
+		}
+
 		return nil
 	default:
 		return e
@@ -724,6 +793,8 @@ func actionBackTab(e *tcell.EventKey) *tcell.EventKey {
 		case FP.TransactionsInputField:
 			return nil
 		case FP.ProfileList:
+			FP.App.SetFocus(FP.GoalsTable)
+		case FP.GoalsTable:
 			FP.App.SetFocus(FP.TransactionsTable)
 		case FP.TransactionsTable:
 			// get the height & width of the transactions table
@@ -769,9 +840,29 @@ func actionBackTab(e *tcell.EventKey) *tcell.EventKey {
 			FP.App.SetFocus(FP.ResultsTable)
 		case FP.ResultsForm:
 			return e
+		case FP.ResultsInputField:
+			return nil
 		}
 
 		return e
+	case PageHistory:
+		switch FP.App.GetFocus() {
+		case FP.HistoryTable:
+			FP.App.SetFocus(FP.HistoryPreview)
+		default:
+			FP.App.SetFocus(FP.HistoryTable)
+		}
+
+		return nil
+	case PageAmortization:
+		switch FP.App.GetFocus() {
+		case FP.AmortizationTable:
+			FP.App.SetFocus(FP.AmortizationSummary)
+		case FP.AmortizationSummary:
+			FP.App.SetFocus(FP.AmortizationTable)
+		}
+
+		return nil
 	}
 
 	return e
@@ -786,6 +877,8 @@ func actionTab(e *tcell.EventKey) *tcell.EventKey {
 			return nil
 		case FP.ProfileList:
 			FP.App.SetFocus(FP.TransactionsTable)
+		case FP.GoalsTable:
+			FP.App.SetFocus(FP.ProfileList)
 		case FP.TransactionsTable:
 			// get the height & width of the transactions table
 			r := FP.TransactionsTable.GetRowCount() - 1
@@ -806,9 +899,9 @@ func actionTab(e *tcell.EventKey) *tcell.EventKey {
 					nc = 0
 					nr = r
 				}
-				// it's more intuitive to go back to the FP.ProfileList
-				// when backtabbing from the first column in the table
-				focusTarget = FP.ProfileList
+				// the goals table comes after the transactions table, and
+				// tabbing out of it goes back to the FP.ProfileList
+				focusTarget = FP.GoalsTable
 			}
 
 			FP.TransactionsTable.Select(nr, nc)
@@ -829,9 +922,29 @@ func actionTab(e *tcell.EventKey) *tcell.EventKey {
 			return nil
 		case FP.ResultsForm:
 			return e
+		case FP.ResultsInputField:
+			return nil
 		}
 
 		return e
+	case PageHistory:
+		switch FP.App.GetFocus() {
+		case FP.HistoryTable:
+			FP.App.SetFocus(FP.HistoryPreview)
+		default:
+			FP.App.SetFocus(FP.HistoryTable)
+		}
+
+		return nil
+	case PageAmortization:
+		switch FP.App.GetFocus() {
+		case FP.AmortizationTable:
+			FP.App.SetFocus(FP.AmortizationSummary)
+		case FP.AmortizationSummary:
+			FP.App.SetFocus(FP.AmortizationTable)
+		}
+
+		return nil
 	}
 
 	return e
@@ -840,10 +953,22 @@ func actionTab(e *tcell.EventKey) *tcell.EventKey {
 func actionEsc(e *tcell.EventKey) *tcell.EventKey {
 	currentFocus := FP.App.GetFocus()
 	switch currentFocus {
-	case FP.TransactionsInputField:
+	case FP.TransactionsInputField, FP.ResultsInputField, FP.AmortizationInputField:
 		return e
 	case FP.TransactionsTable:
-		// deselect the last selected index on the first press
+		// clear any search highlights on the first press
+		if FP.TransactionsSearch.Query != "" {
+			FP.TransactionsSearch.Query = ""
+
+			cr, cc := FP.TransactionsTable.GetSelection()
+
+			getTransactionsTable()
+			FP.TransactionsTable.Select(cr, cc)
+
+			return nil
+		}
+
+		// deselect the last selected index on the next press
 		if FP.LastSelection != -1 {
 			FP.LastSelection = -1
 
@@ -879,11 +1004,39 @@ func actionEsc(e *tcell.EventKey) *tcell.EventKey {
 
 		FP.TransactionsTable.Select(cr, cc)
 		FP.App.SetFocus(FP.TransactionsTable)
+	case FP.GoalsTable:
+		FP.App.SetFocus(FP.TransactionsTable)
+		return nil
 	case FP.ResultsForm:
 		FP.App.SetFocus(FP.ResultsTable)
 		return nil
 	case FP.ResultsTable:
+		if FP.ResultsSearch.Query != "" {
+			FP.ResultsSearch.Query = ""
+
+			applyResultsSearch()
+
+			return nil
+		}
+
+		// go back to the grouped rows after drilling down into one of them
+		if drillUpResultsGroup() {
+			return nil
+		}
+
+		FP.Pages.SwitchToPage(PageProfiles)
+		return nil
+	case FP.HistoryTable, FP.HistoryPreview:
 		FP.Pages.SwitchToPage(PageProfiles)
+		setBottomPageNavText()
+		FP.App.SetFocus(FP.TransactionsTable)
+
+		return nil
+	case FP.AmortizationTable, FP.AmortizationSummary:
+		FP.Pages.SwitchToPage(PageProfiles)
+		setBottomPageNavText()
+		FP.App.SetFocus(FP.TransactionsTable)
+
 		return nil
 	default:
 		promptExit()
@@ -932,6 +1085,107 @@ func actionProfiles() *tcell.EventKey {
 	return nil
 }
 
+func actionHistory() *tcell.EventKey {
+	FP.Pages.SwitchToPage(PageHistory)
+	setBottomPageNavText()
+
+	getHistoryTable()
+
+	FP.App.SetFocus(FP.HistoryTable)
+
+	return nil
+}
+
+func actionLoan(e *tcell.EventKey) *tcell.EventKey {
+	pageName, _ := FP.Pages.GetFrontPage()
+	switch pageName {
+	case PageProfiles:
+		switch FP.App.GetFocus() {
+		case FP.TransactionsTable, FP.ProfileList:
+			cr, cc := FP.TransactionsTable.GetSelection()
+
+			FP.LastSelection = -1
+
+			// the new transaction is inserted once the wizard is complete
+			promptLoanWizard(func(newTX TX) {
+				insertTransactions([]TX{newTX}, cr, cc)
+			})
+
+			return nil
+		default:
+			return e
+		}
+	default:
+		return e
+	}
+}
+
+func actionAmortize(e *tcell.EventKey) *tcell.EventKey {
+	pageName, _ := FP.Pages.GetFrontPage()
+	switch pageName {
+	case PageProfiles:
+		switch FP.App.GetFocus() {
+		case FP.TransactionsTable:
+			cr, _ := FP.TransactionsTable.GetSelection()
+			showAmortization(cr - 1) // skip header
+
+			return nil
+		default:
+			return e
+		}
+	default:
+		return e
+	}
+}
+
+// actionJumpToBalanceAlert jumps to one of the notable days of the results;
+// see BalanceAlerts.
+func actionJumpToBalanceAlert(e *tcell.EventKey, action string) *tcell.EventKey {
+	pageName, _ := FP.Pages.GetFrontPage()
+	switch pageName {
+	case PageResults:
+		switch FP.App.GetFocus() {
+		case FP.ResultsTable, FP.ResultsDescription:
+			alerts := FP.LatestResultsExtra.Alerts
+
+			switch action {
+			case ActionJumpBelow:
+				jumpToBalanceAlert(alerts.BelowMinimum, FP.T["ResultsStatsFirstBelowMinimum"])
+			case ActionJumpNegative:
+				jumpToBalanceAlert(alerts.Negative, FP.T["ResultsStatsFirstNegative"])
+			case ActionJumpLowest:
+				jumpToBalanceAlert(alerts.Lowest, FP.T["ResultsStatsLowestBalance"])
+			}
+
+			return nil
+		default:
+			return e
+		}
+	default:
+		return e
+	}
+}
+
+// actionGroup cycles through the groupings of the results table; see
+// ResultsGroupings.
+func actionGroup(e *tcell.EventKey) *tcell.EventKey {
+	pageName, _ := FP.Pages.GetFrontPage()
+	switch pageName {
+	case PageResults:
+		switch FP.App.GetFocus() {
+		case FP.ResultsTable, FP.ResultsDescription:
+			cycleResultsGrouping()
+			FP.App.SetFocus(FP.ResultsTable)
+
+			return nil
+		default:
+			return e
+		}
+	default:
+		return e
+	}
+}
+
 func actionGlobalHelp() *tcell.EventKey {
 	FP.Pages.SwitchToPage(PageHelp)
 	setBottomPageNavText()
@@ -941,7 +1195,7 @@ func actionGlobalHelp() *tcell.EventKey {
 
 func actionHelp(e *tcell.EventKey) *tcell.EventKey {
 	switch FP.App.GetFocus() {
-	case FP.TransactionsInputField:
+	case FP.TransactionsInputField, FP.ResultsInputField, FP.AmortizationInputField:
 		return e
 	case FP.ResultsForm:
 		return e
@@ -953,6 +1207,81 @@ func actionHelp(e *tcell.EventKey) *tcell.EventKey {
 	}
 }
 
+func actionSearch(e *tcell.EventKey) *tcell.EventKey {
+	pageName, _ := FP.Pages.GetFrontPage()
+	switch pageName {
+	case PageProfiles:
+		switch FP.App.GetFocus() {
+		case FP.TransactionsTable, FP.ProfileList:
+			startTransactionsSearch()
+
+			return nil
+		default:
+			return e
+		}
+	case PageResults:
+		switch FP.App.GetFocus() {
+		case FP.ResultsTable, FP.ResultsDescription:
+			startResultsSearch()
+
+			return nil
+		default:
+			return e
+		}
+	default:
+		return e
+	}
+}
+
+func actionSearchNext(e *tcell.EventKey, forward bool) *tcell.EventKey {
+	pageName, _ := FP.Pages.GetFrontPage()
+	switch pageName {
+	case PageProfiles:
+		if FP.App.GetFocus() == FP.TransactionsInputField && !FP.TransactionsSearch.Active {
+			return e
+		}
+
+		nextSearchMatch(&FP.TransactionsSearch, FP.TransactionsTable, forward)
+
+		if FP.App.GetFocus() != FP.TransactionsInputField {
+			FP.App.SetFocus(FP.TransactionsTable)
+		}
+
+		return nil
+	case PageResults:
+		if FP.App.GetFocus() == FP.ResultsInputField && !FP.ResultsSearch.Active {
+			return e
+		}
+
+		nextSearchMatch(&FP.ResultsSearch, FP.ResultsTable, forward)
+
+		if FP.App.GetFocus() != FP.ResultsInputField {
+			FP.App.SetFocus(FP.ResultsTable)
+		}
+
+		return nil
+	default:
+		return e
+	}
+}
+
+func actionExport(e *tcell.EventKey) *tcell.EventKey {
+	pageName, _ := FP.Pages.GetFrontPage()
+	switch pageName {
+	case PageResults:
+		switch FP.App.GetFocus() {
+		case FP.ResultsInputField:
+			return e
+		default:
+			promptExportResults()
+
+			return nil
+		}
+	default:
+		return e
+	}
+}
+
 // action is the primary decision tree that is triggered when a key event
 // is triggered. Please ensure that every case statement has a return or
 // fallthrough
@@ -964,6 +1293,7 @@ func actionHelp(e *tcell.EventKey) *tcell.EventKey {
 func action(action string, e *tcell.EventKey) *tcell.EventKey {
 	duplicating := false
 	multiSelecting := false
+	forward := false
 
 	switch action {
 	case ActionRedo:
@@ -971,7 +1301,7 @@ func action(action string, e *tcell.EventKey) *tcell.EventKey {
 	case ActionUndo:
 		return actionUndo(e)
 	case ActionQuit:
-		return actionQuit()
+		return actionQuit(e)
 	case ActionMulti:
 		multiSelecting = true
 
@@ -1020,11 +1350,28 @@ func action(action string, e *tcell.EventKey) *tcell.EventKey {
 		return actionProfiles()
 	case ActionGlobalHelp:
 		return actionGlobalHelp()
+	case ActionHistory:
+		return actionHistory()
 	case ActionHelp:
 		return actionHelp(e)
+	case ActionExport:
+		return actionExport(e)
 	case ActionSearch:
-		// searching not implemented yet
+		return actionSearch(e)
+	case ActionSearchNext:
+		forward = true
+
 		fallthrough
+	case ActionSearchPrev:
+		return actionSearchNext(e, forward)
+	case ActionLoan:
+		return actionLoan(e)
+	case ActionAmortize:
+		return actionAmortize(e)
+	case ActionJumpBelow, ActionJumpNegative, ActionJumpLowest:
+		return actionJumpToBalanceAlert(e, action)
+	case ActionGroup:
+		return actionGroup(e)
 	default:
 		return e
 	}
@@ -0,0 +1,310 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	lib "github.com/charles-m-knox/finance-planner-lib"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// This file contains the amortization schedule page, which lists every
+// payment of a loan, split between interest and principal, and lets the user
+// model extra principal payments to see how much sooner the loan is paid off.
+
+// getAmortizationPage builds the amortization schedule page: the schedule on
+// the left, a summary of the loan on the right, and an input field for
+// editing the extra principal at the bottom.
+func getAmortizationPage() *tview.Flex {
+	FP.AmortizationTable = tview.NewTable().SetFixed(1, 0)
+	FP.AmortizationTable.SetBorder(true)
+	FP.AmortizationTable.SetTitle(FP.T["AmortizationTableTitle"])
+	FP.AmortizationTable.SetBorders(false).
+		SetSelectable(true, false).
+		SetSeparator(' ')
+
+	FP.AmortizationSummary = tview.NewTextView().SetDynamicColors(true)
+	FP.AmortizationSummary.SetBorder(true)
+	FP.AmortizationSummary.SetTitle(FP.T["AmortizationSummaryTitle"])
+
+	FP.AmortizationInputField = tview.NewInputField()
+	deactivateAmortizationInputField()
+
+	return tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexColumn).
+			AddItem(FP.AmortizationTable, 0, 2, true).
+			AddItem(FP.AmortizationSummary, 0, 1, false), 0, 1, true).
+		AddItem(FP.AmortizationInputField, 1, 0, false)
+}
+
+// deactivateAmortizationInputField resets the amortization page's input field
+// to its placeholder and focuses the schedule.
+func deactivateAmortizationInputField() {
+	FP.AmortizationInputField.SetDoneFunc(nil)
+	FP.AmortizationInputField.SetFieldBackgroundColor(
+		tcell.ColorNames[FP.Colors["TransactionsInputFieldBlurredBackground"]],
+	)
+	FP.AmortizationInputField.SetLabel(fmt.Sprintf("%v%v%v",
+		FP.Colors["TransactionsInputFieldPassive"],
+		FP.T["AmortizationInputFieldPlaceholderLabel"],
+		Reset,
+	))
+	FP.AmortizationInputField.SetText("")
+
+	FP.App.SetFocus(FP.AmortizationTable)
+}
+
+// getAmortizationTX returns the transaction whose loan is shown on the
+// amortization page, or nil if it no longer exists, such as after an undo.
+func getAmortizationTX() *TX {
+	if FP.SelectedProfile == nil {
+		return nil
+	}
+
+	for i := range FP.SelectedProfile.TX {
+		if FP.SelectedProfile.TX[i].ID == FP.AmortizationTXID && isLoan(&FP.SelectedProfile.TX[i]) {
+			return &FP.SelectedProfile.TX[i]
+		}
+	}
+
+	return nil
+}
+
+// getAmortizationTable clears and re-populates the amortization schedule and
+// the loan's summary.
+func getAmortizationTable() {
+	FP.AmortizationTable.Clear()
+	FP.AmortizationSummary.Clear()
+
+	tx := getAmortizationTX()
+	if tx == nil {
+		FP.AmortizationSummary.SetText(fmt.Sprintf("%v%v%v",
+			FP.Colors["ResultsDescriptionError"],
+			FP.T["AmortizationNoLoan"],
+			Reset,
+		))
+
+		return
+	}
+
+	holidays, err := getHolidays(&FP.Config)
+	if err != nil {
+		setAmortizationError(err)
+
+		return
+	}
+
+	schedule, err := getLoanSchedule(tx, holidays)
+	if err != nil {
+		setAmortizationError(err)
+
+		return
+	}
+
+	headers := []TableCell{
+		{Text: FP.T["AmortizationColumnNumber"], Color: FP.Colors["AmortizationColumnNumber"]},
+		{Text: FP.T["AmortizationColumnDate"], Color: FP.Colors["AmortizationColumnDate"]},
+		{Text: FP.T["AmortizationColumnPayment"], Color: FP.Colors["AmortizationColumnPayment"]},
+		{Text: FP.T["AmortizationColumnInterest"], Color: FP.Colors["AmortizationColumnInterest"]},
+		{Text: FP.T["AmortizationColumnPrincipal"], Color: FP.Colors["AmortizationColumnPrincipal"]},
+		{Text: FP.T["AmortizationColumnExtra"], Color: FP.Colors["AmortizationColumnExtra"]},
+		{Text: FP.T["AmortizationColumnRemaining"], Color: FP.Colors["AmortizationColumnRemaining"], Expand: 1},
+	}
+
+	setAmortizationRow(0, headers)
+
+	for i := range schedule {
+		p := &schedule[i]
+
+		setAmortizationRow(i+1, []TableCell{
+			{Text: fmt.Sprint(i + 1), Color: FP.Colors["AmortizationColumnNumber"]},
+			{Text: lib.GetNowDateString(p.Date), Color: FP.Colors["AmortizationColumnDate"]},
+			{Text: lib.FormatAsCurrency(p.Payment), Color: FP.Colors["AmortizationColumnPayment"]},
+			{Text: lib.FormatAsCurrency(p.Interest), Color: FP.Colors["AmortizationColumnInterest"]},
+			{Text: lib.FormatAsCurrency(p.Principal), Color: FP.Colors["AmortizationColumnPrincipal"]},
+			{Text: lib.FormatAsCurrency(p.Extra), Color: FP.Colors["AmortizationColumnExtra"]},
+			{Text: lib.FormatAsCurrency(p.Remaining), Color: FP.Colors["AmortizationColumnRemaining"], Expand: 1},
+		})
+	}
+
+	FP.AmortizationTable.Select(1, 0).ScrollToBeginning()
+
+	// the same loan without extra principal, to show what it saves
+	base := *tx
+	base.Loan.ExtraPrincipal = 0
+
+	baseSchedule, err := getLoanSchedule(&base, holidays)
+	if err != nil {
+		setAmortizationError(err)
+
+		return
+	}
+
+	FP.AmortizationSummary.SetText(getAmortizationSummary(tx, schedule, baseSchedule))
+}
+
+// setAmortizationRow sets the cells of the i'th row of the amortization
+// schedule.
+func setAmortizationRow(i int, cells []TableCell) {
+	for j := range cells {
+		cell := tview.NewTableCell(fmt.Sprintf("%v%v%v", cells[j].Color, cells[j].Text, Reset))
+		if cells[j].Expand > 0 {
+			cell.SetExpansion(cells[j].Expand)
+		}
+
+		FP.AmortizationTable.SetCell(i, j, cell)
+	}
+}
+
+// setAmortizationError shows an error in place of the loan's summary.
+func setAmortizationError(err error) {
+	FP.AmortizationSummary.SetText(fmt.Sprintf("%v%v%v",
+		FP.Colors["ResultsDescriptionError"],
+		tview.Escape(err.Error()),
+		Reset,
+	))
+}
+
+// getAmortizationSummary returns the loan's terms and totals as text. If the
+// loan has extra principal, the interest and the number of payments that it
+// saves, compared to the base schedule without it, are included.
+func getAmortizationSummary(tx *TX, schedule, baseSchedule []AmortizationPayment) string {
+	interest, paid := getAmortizationTotals(schedule)
+
+	payoff := ""
+	if len(schedule) > 0 {
+		payoff = lib.GetNowDateString(schedule[len(schedule)-1].Date)
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("%v[::b]%v[-:-:-:-]\n\n", FP.Colors["AmortizationSummary"], tview.Escape(tx.Name)))
+
+	lines := [][]string{
+		{FP.T["AmortizationSummaryPrincipal"], lib.FormatAsCurrency(tx.Loan.Principal)},
+		{FP.T["AmortizationSummaryRate"], fmt.Sprintf("%v%%", formatInterestRate(tx.Loan.Rate))},
+		{FP.T["AmortizationSummaryTerm"], fmt.Sprint(tx.Loan.Term)},
+		{FP.T["AmortizationSummaryPayment"], lib.FormatAsCurrency(getLoanPayment(tx.Loan))},
+		{FP.T["AmortizationSummaryExtra"], lib.FormatAsCurrency(tx.Loan.ExtraPrincipal)},
+		{FP.T["AmortizationSummaryPayments"], fmt.Sprint(len(schedule))},
+		{FP.T["AmortizationSummaryPayoff"], payoff},
+		{FP.T["AmortizationSummaryInterest"], lib.FormatAsCurrency(interest)},
+		{FP.T["AmortizationSummaryPaid"], lib.FormatAsCurrency(paid)},
+	}
+
+	for _, line := range lines {
+		sb.WriteString(fmt.Sprintf("%v: %v\n", line[0], line[1]))
+	}
+
+	sb.WriteString(Reset)
+
+	if tx.Loan.ExtraPrincipal > 0 {
+		baseInterest, _ := getAmortizationTotals(baseSchedule)
+
+		sb.WriteString(fmt.Sprintf("\n%v%v: %v\n%v: %v%v\n",
+			FP.Colors["AmortizationSummarySavings"],
+			FP.T["AmortizationSummaryInterestSaved"],
+			lib.FormatAsCurrency(baseInterest-interest),
+			FP.T["AmortizationSummaryPaymentsSaved"],
+			len(baseSchedule)-len(schedule),
+			Reset,
+		))
+	}
+
+	sb.WriteString(fmt.Sprintf("\n%v%v: %v%v",
+		FP.Colors["ResultsDescriptionPassive"],
+		getBinding(ActionEdit),
+		FP.T["AmortizationSummaryHint"],
+		Reset,
+	))
+
+	return sb.String()
+}
+
+// showAmortization opens the amortization schedule page for the i'th
+// transaction of the selected profile, if it is a loan.
+func showAmortization(i int) {
+	if i < 0 || i >= len(FP.SelectedProfile.TX) || !isLoan(&FP.SelectedProfile.TX[i]) {
+		FP.ProfileStatusText.SetText(fmt.Sprintf("%v%v%v",
+			FP.Colors["ProfileStatusTextPassive"],
+			FP.T["AmortizationNotALoan"],
+			Reset,
+		))
+
+		return
+	}
+
+	FP.AmortizationTXID = FP.SelectedProfile.TX[i].ID
+
+	FP.Pages.SwitchToPage(PageAmortization)
+	setBottomPageNavText()
+
+	getAmortizationTable()
+	deactivateAmortizationInputField()
+}
+
+// editAmortizationExtraPrincipal prompts for the extra principal that is paid
+// with every payment of the loan that is shown on the amortization page. The
+// loan's transaction is updated to match the new schedule.
+func editAmortizationExtraPrincipal() {
+	tx := getAmortizationTX()
+	if tx == nil {
+		return
+	}
+
+	label := fmt.Sprintf("%v:", FP.T["AmortizationInputFieldExtraLabel"])
+
+	FP.AmortizationInputField.SetFieldBackgroundColor(
+		tcell.ColorNames[FP.Colors["TransactionsInputFieldFocusedBackground"]],
+	)
+	FP.AmortizationInputField.SetLabel(fmt.Sprintf("%v%v%v", FP.Colors["TransactionsInputFieldActive"], label, Reset))
+	FP.AmortizationInputField.SetText(lib.FormatAsCurrency(tx.Loan.ExtraPrincipal))
+
+	FP.AmortizationInputField.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEscape {
+			deactivateAmortizationInputField()
+
+			return
+		}
+
+		extra := int(lib.ParseDollarAmount(FP.AmortizationInputField.GetText(), true))
+		if extra < 0 {
+			FP.AmortizationInputField.SetLabel(fmt.Sprintf("%v:", FP.T["AmortizationInputFieldInvalidExtraLabel"]))
+
+			return // don't drop focus - the user entered invalid input
+		}
+
+		holidays, err := getHolidays(&FP.Config)
+		if err != nil {
+			FP.AmortizationInputField.SetLabel(fmt.Sprintf("%v %v", tview.Escape(err.Error()), label))
+
+			return
+		}
+
+		tx = getAmortizationTX()
+		if tx != nil {
+			tx.Loan.ExtraPrincipal = extra
+
+			err = applyLoan(tx, holidays)
+			if err != nil {
+				FP.AmortizationInputField.SetLabel(fmt.Sprintf("%v %v", tview.Escape(err.Error()), label))
+
+				return
+			}
+
+			modified()
+
+			cr, cc := FP.TransactionsTable.GetSelection()
+
+			getTransactionsTable()
+			FP.TransactionsTable.Select(cr, cc)
+		}
+
+		getAmortizationTable()
+		deactivateAmortizationInputField()
+	})
+
+	FP.App.SetFocus(FP.AmortizationInputField)
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/rivo/tview"
+	"gopkg.in/yaml.v3"
+)
+
+// This file contains the logic for tracking which profiles have unsaved
+// changes, and for automatically saving the config.
+
+// markSaved records the current state of every profile as saved, so that
+// getUnsavedProfiles can later tell which profiles have changed since.
+func markSaved() {
+	setSavedState(&FP.Config)
+}
+
+// setSavedState records conf as the version of the config that is on disk.
+// It is used as the base when merging external changes, and each of its
+// profiles is compared against the loaded profiles by getUnsavedProfiles.
+func setSavedState(conf *Config) {
+	b, err := yaml.Marshal(conf)
+	if err == nil {
+		FP.SavedConfig = b
+	}
+
+	FP.SavedProfiles = make(map[string][]byte, len(conf.Profiles))
+
+	for i := range conf.Profiles {
+		b, err := yaml.Marshal(conf.Profiles[i])
+		if err != nil {
+			continue
+		}
+
+		FP.SavedProfiles[conf.Profiles[i].Name] = b
+	}
+
+	getUnsavedProfiles()
+}
+
+// getUnsavedProfiles returns the names of all profiles that differ from when
+// the config was last loaded or saved, including profiles that have been
+// added or deleted since. Deleted profiles are suffixed accordingly.
+//
+// Profiles are compared by their serialized form rather than by the Modified
+// flag, because undo and redo replace the profiles entirely.
+func getUnsavedProfiles() []string {
+	unsaved := []string{}
+	seen := make(map[string]bool, len(FP.Config.Profiles))
+
+	for i := range FP.Config.Profiles {
+		p := &(FP.Config.Profiles[i])
+		seen[p.Name] = true
+
+		saved, ok := FP.SavedProfiles[p.Name]
+
+		b, err := yaml.Marshal(p)
+		if !ok || err != nil || !bytes.Equal(saved, b) {
+			p.Modified = true
+			unsaved = append(unsaved, p.Name)
+
+			continue
+		}
+
+		p.Modified = false
+	}
+
+	deleted := []string{}
+
+	for name := range FP.SavedProfiles {
+		if !seen[name] {
+			deleted = append(deleted, fmt.Sprintf("%v %v", name, FP.T["PromptExitProfileDeleted"]))
+		}
+	}
+
+	slices.Sort(deleted)
+
+	return append(unsaved, deleted...)
+}
+
+// autosave saves the config if any profile has unsaved changes, and updates
+// the status text to say so. Like checkConfigFile, it waits until no prompt is
+// being shown, so that an external change to the config file can't replace
+// the prompt; the changes are saved by the next autosave instead.
+func autosave() {
+	p, _ := FP.Pages.GetFrontPage()
+	if p == PagePrompt {
+		return
+	}
+
+	if len(getUnsavedProfiles()) == 0 || guardExternalConfigChange() {
+		return
+	}
+
+	err := saveConfig(FP.FlagConfigFile, &FP.Config)
+	if err != nil {
+		FP.ProfileStatusText.SetText(fmt.Sprintf("%v%v: %v%v",
+			FP.Colors["ProfileStatusTextError"],
+			FP.T["AutosaveFailed"],
+			tview.Escape(err.Error()),
+			Reset,
+		))
+
+		return
+	}
+
+	markSaved()
+
+	FP.ProfileStatusText.SetText(fmt.Sprintf("%v%v %v%v",
+		FP.Colors["ProfileStatusTextPassive"],
+		FP.T["AutosaveSucceeded"],
+		time.Now().Format(time.TimeOnly),
+		Reset,
+	))
+}
+
+// startAutosave periodically runs autosave in the background, according to
+// Config.AutosaveInterval. Does nothing if the interval is not set.
+func startAutosave() {
+	if FP.Config.AutosaveInterval < 1 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(time.Duration(FP.Config.AutosaveInterval) * time.Second)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			FP.App.QueueUpdateDraw(autosave)
+		}
+	}()
+}
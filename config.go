@@ -8,8 +8,10 @@ import (
 	"log"
 	"os"
 	"path"
+	"path/filepath"
+	"slices"
+	"time"
 
-	lib "github.com/charles-m-knox/finance-planner-lib"
 	"github.com/charles-m-knox/go-uuid"
 
 	"github.com/adrg/xdg"
@@ -69,6 +71,150 @@ func fileExists(name string) (bool, error) {
 	return false, err
 }
 
+// writeFileAtomic writes b to a temporary file in the same directory as name
+// and then renames it over name, so that name is never left partially
+// written if something goes wrong midway.
+func writeFileAtomic(name string, b []byte, perm os.FileMode) error {
+	f, err := os.CreateTemp(filepath.Dir(name), fmt.Sprintf(".%v.*.tmp", filepath.Base(name)))
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file for %v: %w", name, err)
+	}
+
+	tmp := f.Name()
+
+	_, err = f.Write(b)
+	if err == nil {
+		err = f.Sync()
+	}
+
+	closeErr := f.Close()
+	if err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Chmod(tmp, perm)
+	}
+
+	if err == nil {
+		err = os.Rename(tmp, name)
+	}
+
+	if err != nil {
+		_ = os.Remove(tmp)
+
+		return fmt.Errorf("failed to write %v: %w", name, err)
+	}
+
+	return nil
+}
+
+// The time format used in the names of config backups. It sorts
+// lexicographically in chronological order.
+const BackupTimeFormat = "20060102T150405.000"
+
+// The number of backups of the config file that are kept when the config
+// doesn't set Config.BackupCount.
+const DefaultBackupCount = 5
+
+// getBackupCount returns the number of backups of the config file to keep.
+func getBackupCount(conf *Config) int {
+	if conf.BackupCount == nil {
+		return DefaultBackupCount
+	}
+
+	return *conf.BackupCount
+}
+
+// getBackupGlob returns the glob pattern that matches every backup of file.
+func getBackupGlob(file string) string {
+	return fmt.Sprintf("%v.*.bak", file)
+}
+
+// backupConfig copies the current contents of file to a timestamped backup
+// next to it, such as "config.yml.20240102T150405.000.bak", and then deletes
+// the oldest backups so that at most count remain. Does nothing if count is
+// less than 1 or file does not exist yet.
+func backupConfig(file string, count int, now time.Time) error {
+	if count < 1 {
+		return nil
+	}
+
+	info, err := os.Stat(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to stat %v: %w", file, err)
+	}
+
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("failed to read %v: %w", file, err)
+	}
+
+	backup := fmt.Sprintf("%v.%v.bak", file, now.Format(BackupTimeFormat))
+
+	err = writeFileAtomic(backup, b, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	backups, err := filepath.Glob(getBackupGlob(file))
+	if err != nil {
+		return fmt.Errorf("failed to list backups of %v: %w", file, err)
+	}
+
+	slices.Sort(backups)
+
+	for len(backups) > count {
+		err = os.Remove(backups[0])
+		if err != nil {
+			return fmt.Errorf("failed to remove old backup %v: %w", backups[0], err)
+		}
+
+		backups = backups[1:]
+	}
+
+	return nil
+}
+
+// saveConfig serializes conf and writes it to file atomically, after first
+// backing up the existing file according to getBackupCount. The existing
+// file's permissions are kept.
+func saveConfig(file string, conf *Config) error {
+	if conf.Version == "" {
+		conf.Version = ConfigVersion
+	}
+
+	b, err := yaml.Marshal(conf)
+	if err != nil {
+		return fmt.Errorf("%v: %w", FP.T["SaveMarshalFailed"], err)
+	}
+
+	perm := os.FileMode(0o644)
+
+	info, err := os.Stat(file)
+	if err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	err = backupConfig(file, getBackupCount(conf), time.Now())
+	if err != nil {
+		return fmt.Errorf("%v: %w", FP.T["SaveBackupFailed"], err)
+	}
+
+	err = writeFileAtomic(file, b, perm)
+	if err != nil {
+		return fmt.Errorf("%v: %w", FP.T["SaveWriteFailed"], err)
+	}
+
+	if file == FP.FlagConfigFile {
+		recordConfigFileState(b)
+	}
+
+	return nil
+}
+
 // Attempts to load from the "file" path provided - if not successful,
 // attempts to load from xdg config, then xdg home.
 //
@@ -190,7 +336,7 @@ func JSONtoYAML() {
 		Profiles: []Profile{
 			{
 				Name: "migrated",
-				TX:   []lib.TX{},
+				TX:   []TX{},
 			},
 		},
 	}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestGetBackupCount(t *testing.T) {
+	tests := []struct {
+		name string
+		conf string
+		want int
+	}{
+		{
+			name: "unset",
+			conf: "version: \"1\"\n",
+			want: DefaultBackupCount,
+		},
+		{
+			name: "disabled",
+			conf: "backupCount: 0\n",
+			want: 0,
+		},
+		{
+			name: "set",
+			conf: "backupCount: 12\n",
+			want: 12,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := Config{}
+
+			err := yaml.Unmarshal([]byte(tt.conf), &conf)
+			if err != nil {
+				t.Fatalf("failed to unmarshal: %v", err)
+			}
+
+			if got := getBackupCount(&conf); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+
+			// the setting is saved as it was loaded
+			b, err := yaml.Marshal(&conf)
+			if err != nil {
+				t.Fatalf("failed to marshal: %v", err)
+			}
+
+			if got, want := strings.Contains(string(b), "backupCount:"), strings.Contains(tt.conf, "backupCount:"); got != want {
+				t.Errorf("got backupCount saved: %v, want %v", got, want)
+			}
+		})
+	}
+}
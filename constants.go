@@ -1,25 +1,30 @@
 package main
 
 const (
-	ColumnOrder     = "Order"
-	ColumnAmount    = "Amount"    // int in cents; 500 = $5.00
-	ColumnActive    = "Active"    // bool true/false
-	ColumnName      = "Name"      // editable string
-	ColumnFrequency = "Frequency" // dropdown, monthly/daily/weekly/yearly
-	ColumnInterval  = "Interval"  // integer, occurs every x frequency
-	ColumnMonday    = "Monday"    // bool
-	ColumnTuesday   = "Tuesday"   // bool
-	ColumnWednesday = "Wednesday" // bool
-	ColumnThursday  = "Thursday"  // bool
-	ColumnFriday    = "Friday"    // bool
-	ColumnSaturday  = "Saturday"  // bool
-	ColumnSunday    = "Sunday"    // bool
-	ColumnStarts    = "Starts"    // string
-	ColumnEnds      = "Ends"      // string
-	ColumnNote      = "Note"      // editable string
-	ColumnID        = "ID"
-	ColumnCreatedAt = "CreatedAt"
-	ColumnUpdatedAt = "UpdatedAt"
+	ColumnOrder       = "Order"
+	ColumnAmount      = "Amount"      // int in cents; 500 = $5.00
+	ColumnActive      = "Active"      // bool true/false
+	ColumnName        = "Name"        // editable string
+	ColumnFrequency   = "Frequency"   // dropdown, daily/businessdays/weekly/monthly/yearly
+	ColumnInterval    = "Interval"    // integer, occurs every x frequency
+	ColumnMonday      = "Monday"      // bool
+	ColumnTuesday     = "Tuesday"     // bool
+	ColumnWednesday   = "Wednesday"   // bool
+	ColumnThursday    = "Thursday"    // bool
+	ColumnFriday      = "Friday"      // bool
+	ColumnSaturday    = "Saturday"    // bool
+	ColumnSunday      = "Sunday"      // bool
+	ColumnStarts      = "Starts"      // string
+	ColumnEnds        = "Ends"        // string
+	ColumnOccurrences = "Occurrences" // integer, 0 for unlimited
+	ColumnRoll        = "Roll"        // dropdown, none/previous/next
+	ColumnRRule       = "RRule"       // editable string, overrides frequency/interval/weekdays
+	ColumnAccount     = "Account"     // dropdown, one of the profile's accounts
+	ColumnTransferTo  = "TransferTo"  // dropdown, one of the profile's accounts
+	ColumnNote        = "Note"        // editable string
+	ColumnID          = "ID"
+	ColumnCreatedAt   = "CreatedAt"
+	ColumnUpdatedAt   = "UpdatedAt"
 
 	WeekdayMonday    = "Monday"
 	WeekdayTuesday   = "Tuesday"
@@ -29,9 +34,11 @@ const (
 	WeekdaySaturday  = "Saturday"
 	WeekdaySunday    = "Sunday"
 
-	WEEKLY  = "WEEKLY"
-	MONTHLY = "MONTHLY"
-	YEARLY  = "YEARLY"
+	DAILY        = "DAILY"
+	BUSINESSDAYS = "BUSINESSDAYS" // weekdays, excluding Config.Holidays
+	WEEKLY       = "WEEKLY"
+	MONTHLY      = "MONTHLY"
+	YEARLY       = "YEARLY"
 
 	New = "New"
 
@@ -49,6 +56,20 @@ const (
 	ConfigVersion = "1"
 )
 
+// Subcommands that run without starting the terminal user interface.
+const (
+	CommandResults = "results"
+	CommandMerge   = "merge"
+)
+
+// Output formats that results can be written in.
+const (
+	FormatTable    = "table"
+	FormatCSV      = "csv"
+	FormatJSON     = "json"
+	FormatMarkdown = "markdown"
+)
+
 // Reset causes any tcell styling to be unset.
 const Reset = "[-:-:-:-]"
 
@@ -85,25 +106,30 @@ const (
 )
 
 const (
-	ColorColumnOrder     = "[gray]"
-	ColorColumnAmount    = "[gold]"
-	ColorColumnActive    = "[white]"
-	ColorColumnName      = "[#8899dd]"
-	ColorColumnFrequency = "[#70dd70]"
-	ColorColumnInterval  = "[#de9a9a]"
-	ColorColumnMonday    = "[red]"
-	ColorColumnTuesday   = "[orange]"
-	ColorColumnWednesday = "[yellow]"
-	ColorColumnThursday  = "[green]"
-	ColorColumnFriday    = "[blue]"
-	ColorColumnSaturday  = "[indigo]"
-	ColorColumnSunday    = "[violet]"
-	ColorColumnStarts    = "[#aaffaa]"
-	ColorColumnEnds      = "[#aaffee]"
-	ColorColumnNote      = "[white]"
-	ColorColumnID        = "[gray]"
-	ColorColumnCreatedAt = "[blue]"
-	ColorColumnUpdatedAT = "[blue]"
+	ColorColumnOrder       = "[gray]"
+	ColorColumnAmount      = "[gold]"
+	ColorColumnActive      = "[white]"
+	ColorColumnName        = "[#8899dd]"
+	ColorColumnFrequency   = "[#70dd70]"
+	ColorColumnInterval    = "[#de9a9a]"
+	ColorColumnMonday      = "[red]"
+	ColorColumnTuesday     = "[orange]"
+	ColorColumnWednesday   = "[yellow]"
+	ColorColumnThursday    = "[green]"
+	ColorColumnFriday      = "[blue]"
+	ColorColumnSaturday    = "[indigo]"
+	ColorColumnSunday      = "[violet]"
+	ColorColumnStarts      = "[#aaffaa]"
+	ColorColumnEnds        = "[#aaffee]"
+	ColorColumnOccurrences = "[#aaddff]"
+	ColorColumnRoll        = "[#ffccaa]"
+	ColorColumnRRule       = "[#ddaaff]"
+	ColorColumnAccount     = "[#ffaadd]"
+	ColorColumnTransferTo  = "[#ffaadd]"
+	ColorColumnNote        = "[white]"
+	ColorColumnID          = "[gray]"
+	ColorColumnCreatedAt   = "[blue]"
+	ColorColumnUpdatedAT   = "[blue]"
 
 	ColorInactive = "[gray::i]"
 
@@ -139,6 +165,17 @@ const (
 	ActionGlobalHelp = "globalhelp" // e.g. F1 key instead of ?
 	ActionHelp       = "help"       // e.g. ? key that can also be used in input fields
 	ActionSearch     = "search"
+	ActionExport     = "export"
+	ActionSearchNext = "searchnext"
+	ActionSearchPrev = "searchprev"
+	ActionHistory    = "history"
+	ActionLoan       = "loan"
+	ActionAmortize   = "amortize"
+
+	ActionJumpBelow    = "jumpbelow"
+	ActionJumpNegative = "jumpnegative"
+	ActionJumpLowest   = "jumplowest"
+	ActionGroup        = "group"
 )
 
 var AllActions = []string{
@@ -169,6 +206,16 @@ var AllActions = []string{
 	ActionGlobalHelp,
 	ActionHelp,
 	ActionSearch,
+	ActionExport,
+	ActionSearchNext,
+	ActionSearchPrev,
+	ActionHistory,
+	ActionLoan,
+	ActionAmortize,
+	ActionJumpBelow,
+	ActionJumpNegative,
+	ActionJumpLowest,
+	ActionGroup,
 }
 
 var DefaultMappings = map[string]string{
@@ -203,6 +250,18 @@ var DefaultMappings = map[string]string{
 	DefaultBindingGlobalHelp: ActionGlobalHelp,
 	DefaultBindingHelp:       ActionHelp,
 	DefaultBindingSearch:     ActionSearch,
+	DefaultBindingSearch2:    ActionSearch,
+	DefaultBindingExport:     ActionExport,
+	DefaultBindingSearchNext: ActionSearchNext,
+	DefaultBindingSearchPrev: ActionSearchPrev,
+	DefaultBindingHistory:    ActionHistory,
+	DefaultBindingLoan:       ActionLoan,
+	DefaultBindingAmortize:   ActionAmortize,
+
+	DefaultBindingJumpBelow:    ActionJumpBelow,
+	DefaultBindingJumpNegative: ActionJumpNegative,
+	DefaultBindingJumpLowest:   ActionJumpLowest,
+	DefaultBindingGroup:        ActionGroup,
 }
 
 // For now, please keep all explanations under 80 chars.
@@ -215,8 +274,8 @@ const (
 	ActionExplanationMove       = "moves all selected transactions to the highlighted row"
 	ActionExplanationDelete     = "deletes all selected transactions or current profile"
 	ActionExplanationDuplicate  = "duplicates all selected transactions"
-	ActionExplanationAdd        = "adds a new transaction to the transactions table"
-	ActionExplanationEdit       = "rename the current profile when profile list is focused"
+	ActionExplanationAdd        = "adds a new transaction, optionally from a template, to the transactions table"
+	ActionExplanationEdit       = "rename profile, edit Amount column's schedule, or a loan's extra principal"
 	ActionExplanationSave       = "saves the current file"
 	ActionExplanationEnd        = "context-specific movement to the end of the row/column/line/bounds"
 	ActionExplanationHome       = "context-specific movement to the start of the row/column/line/bounds"
@@ -233,7 +292,18 @@ const (
 	ActionExplanationProfiles   = "immediately takes you to the profiles page"
 	ActionExplanationGlobalHelp = "immediately takes you to the help page"
 	ActionExplanationHelp       = "context-specific help, if available; otherwise, help page"
-	ActionExplanationSearch     = "(not implemented yet!) search (via fuzzy find) in the current table"
+	ActionExplanationSearch     = "search (via fuzzy find) in the current table; up/down keys cycle matches"
+	ActionExplanationExport     = "exports the results table to a .csv, .json, .md, or .txt file"
+	ActionExplanationSearchNext = "moves to the next search match in the current table"
+	ActionExplanationSearchPrev = "moves to the previous search match in the current table"
+	ActionExplanationHistory    = "takes you to the undo history page to browse and jump between snapshots"
+	ActionExplanationLoan       = "adds a loan's payments after prompting for its principal, rate, term & start"
+	ActionExplanationAmortize   = "shows the amortization schedule of the highlighted loan transaction"
+
+	ActionExplanationJumpBelow    = "jumps to the first results row below the profile's minimum balance"
+	ActionExplanationJumpNegative = "jumps to the first results row with a negative balance"
+	ActionExplanationJumpLowest   = "jumps to the results row with the lowest balance"
+	ActionExplanationGroup        = "groups the results by day, week, month, quarter or year"
 )
 
 var ActionExplanations = map[string]string{
@@ -264,6 +334,17 @@ var ActionExplanations = map[string]string{
 	ActionGlobalHelp: ActionExplanationGlobalHelp,
 	ActionHelp:       ActionExplanationHelp,
 	ActionSearch:     ActionExplanationSearch,
+	ActionExport:     ActionExplanationExport,
+	ActionSearchNext: ActionExplanationSearchNext,
+	ActionSearchPrev: ActionExplanationSearchPrev,
+	ActionHistory:    ActionExplanationHistory,
+	ActionLoan:       ActionExplanationLoan,
+	ActionAmortize:   ActionExplanationAmortize,
+
+	ActionJumpBelow:    ActionExplanationJumpBelow,
+	ActionJumpNegative: ActionExplanationJumpNegative,
+	ActionJumpLowest:   ActionExplanationJumpLowest,
+	ActionGroup:        ActionExplanationGroup,
 }
 
 const (
@@ -298,6 +379,18 @@ const (
 	DefaultBindingGlobalHelp = "F1"
 	DefaultBindingHelp       = "Rune[?]"
 	DefaultBindingSearch     = "Rune[/]"
+	DefaultBindingSearch2    = "Ctrl+F"
+	DefaultBindingExport     = "Ctrl+E"
+	DefaultBindingSearchNext = "Ctrl+G"
+	DefaultBindingSearchPrev = "Ctrl+P"
+	DefaultBindingHistory    = "F4"
+	DefaultBindingLoan       = "Rune[l]"
+	DefaultBindingAmortize   = "Rune[L]"
+
+	DefaultBindingJumpBelow    = "Rune[b]"
+	DefaultBindingJumpNegative = "Rune[-]"
+	DefaultBindingJumpLowest   = "Rune[v]"
+	DefaultBindingGroup        = "Rune[g]"
 )
 
 // Magic numbers that are used in multiple places.
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	lib "github.com/charles-m-knox/finance-planner-lib"
+)
+
+// This file contains the logic for credit card accounts. Transactions that
+// belong to a credit card accumulate on it during each statement cycle, and
+// on the statement's due date, a payment is generated that transfers the
+// statement balance from the card's funding account, so that e.g. a checking
+// account's balance reflects when card purchases are actually paid for.
+
+const (
+	// The type of account that credit cards are.
+	AccountTypeCreditCard = "CREDITCARD"
+
+	// The prefix of a credit card's settings when accounts are edited as
+	// text, such as "visa $-200.00 card:15/25>checking".
+	CreditCardPrefix = "card:"
+
+	// Separates the statement day from the due days in a credit card's
+	// settings.
+	CreditCardDueDaysSeparator = "/"
+
+	// Separates the due days from the funding account in a credit card's
+	// settings.
+	CreditCardFundingSeparator = ">"
+
+	// The number of days after a statement closes that its payment is due,
+	// when it is not set.
+	DefaultCreditCardDueDays = 25
+
+	// How a payment that is due on a weekend or holiday is rolled. Card
+	// issuers accept such payments on the next business day.
+	CreditCardPaymentRoll = RollNext
+)
+
+// isCreditCard returns true if the account is a credit card.
+func isCreditCard(a *Account) bool {
+	return strings.EqualFold(a.Type, AccountTypeCreditCard)
+}
+
+// isCreditCardName returns true if the profile's account with the given name
+// is a credit card.
+func isCreditCardName(p *Profile, account string) bool {
+	for i := range p.Accounts {
+		if p.Accounts[i].Name == account {
+			return isCreditCard(&p.Accounts[i])
+		}
+	}
+
+	return false
+}
+
+// getCreditCardDueDays returns the number of days after a statement closes
+// that its payment is due.
+func getCreditCardDueDays(a *Account) int {
+	if a.DueDays < 1 {
+		return DefaultCreditCardDueDays
+	}
+
+	return a.DueDays
+}
+
+// getStatementDates returns the dates, from start to end (inclusive), on
+// which the credit card's statements close. Statements close on the card's
+// statement day every month, or on the last day of months that are shorter.
+func getStatementDates(a *Account, start, end time.Time) []time.Time {
+	dates := []time.Time{}
+
+	for m := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC); !m.After(end); m = m.AddDate(0, 1, 0) {
+		last := m.AddDate(0, 1, -1).Day()
+		d := m.AddDate(0, 0, min(max(a.StatementDay, 1), last)-1)
+
+		if d.Before(start) || d.After(end) {
+			continue
+		}
+
+		dates = append(dates, d)
+	}
+
+	return dates
+}
+
+// getCreditCardPaymentName returns the name of a generated credit card
+// payment, such as "visa payment (2024-01-15)".
+func getCreditCardPaymentName(a *Account, statement time.Time) string {
+	return fmt.Sprintf("%v %v (%v)", a.Name, FP.T["CreditCardPaymentName"], statement.Format(time.DateOnly))
+}
+
+// validateCreditCards returns an error if any of the profile's credit cards
+// has an invalid statement day, or a funding account that doesn't exist or is
+// itself a credit card.
+func validateCreditCards(p *Profile) error {
+	for i := range p.Accounts {
+		a := &p.Accounts[i]
+		if !isCreditCard(a) {
+			continue
+		}
+
+		if a.StatementDay < 1 || a.StatementDay > lib.DaysInMonth {
+			return fmt.Errorf("%v: %v", a.Name, FP.T["CreditCardInvalidStatementDay"])
+		}
+
+		err := validateAccount(p, a.FundingAccount)
+		if err != nil {
+			return fmt.Errorf("%v: %w", a.Name, err)
+		}
+
+		for j := range p.Accounts {
+			if p.Accounts[j].Name == a.FundingAccount && isCreditCard(&p.Accounts[j]) {
+				return fmt.Errorf("%v: %v", a.Name, FP.T["CreditCardInvalidFundingAccount"])
+			}
+		}
+	}
+
+	return nil
+}
+
+// getCreditCardPayments returns the payments of the credit card's statements
+// that close from start to end, as transfers from its funding account. Each
+// payment settles the card's balance when the statement closed, which
+// includes the card's starting balance, minus the payments of the previous
+// statements.
+func getCreditCardPayments(
+	p *Profile,
+	a *Account,
+	startDate, endDate time.Time,
+	holidays HolidayCalendar,
+) ([]TX, error) {
+	payments := []TX{}
+
+	txs, err := prepareTransactions(getAccountTransactions(p.TX, a.Name), startDate, endDate, holidays)
+	if err != nil {
+		return payments, err
+	}
+
+	// the card's balance on every day, without any payments
+	results, err := lib.GetResults(txs, startDate, endDate, getAccountStartingBalance(p, a.Name), func(_ string) {})
+	if err != nil {
+		return payments, err
+	}
+
+	balances := make(map[time.Time]int, len(results))
+	for i := range results {
+		balances[results[i].Date] = results[i].Balance
+	}
+
+	dueDays := getCreditCardDueDays(a)
+	paid := 0
+
+	for _, statement := range getStatementDates(a, startDate, endDate) {
+		balance := balances[statement] + paid
+		if balance >= 0 {
+			continue
+		}
+
+		due := statement.AddDate(0, 0, dueDays)
+
+		tx := TX{
+			TX: lib.TX{
+				Active: true,
+				Name:   getCreditCardPaymentName(a, statement),
+				Amount: -balance,
+				RRule:  getRRuleSet([]time.Time{due}),
+			},
+			Roll:       CreditCardPaymentRoll,
+			Account:    a.FundingAccount,
+			TransferTo: a.Name,
+		}
+
+		paid -= balance
+		payments = append(payments, tx)
+	}
+
+	return payments, nil
+}
+
+// getAllCreditCardPayments returns the payments of all of the profile's
+// credit cards from start to end.
+func getAllCreditCardPayments(p *Profile, startDate, endDate time.Time, holidays HolidayCalendar) ([]TX, error) {
+	payments := []TX{}
+
+	for i := range p.Accounts {
+		a := &p.Accounts[i]
+		if !isCreditCard(a) {
+			continue
+		}
+
+		cardPayments, err := getCreditCardPayments(p, a, startDate, endDate, holidays)
+		if err != nil {
+			return payments, fmt.Errorf("%v: %w", a.Name, err)
+		}
+
+		payments = append(payments, cardPayments...)
+	}
+
+	return payments, nil
+}
+
+// formatCreditCard formats a credit card's settings as text, such as
+// "card:15/25>checking". It can be parsed with parseCreditCard.
+func formatCreditCard(a *Account) string {
+	s := fmt.Sprintf("%v%v%v%v", CreditCardPrefix, a.StatementDay, CreditCardDueDaysSeparator, getCreditCardDueDays(a))
+	if a.FundingAccount != "" {
+		s = fmt.Sprintf("%v%v%v", s, CreditCardFundingSeparator, a.FundingAccount)
+	}
+
+	return s
+}
+
+// parseCreditCard parses a credit card's settings that were formatted with
+// formatCreditCard into the account. The due days and the funding account are
+// optional.
+func parseCreditCard(s string, a *Account) error {
+	settings := strings.TrimPrefix(strings.TrimSpace(s), CreditCardPrefix)
+
+	cycle, funding, _ := strings.Cut(settings, CreditCardFundingSeparator)
+	day, dueDays, hasDueDays := strings.Cut(cycle, CreditCardDueDaysSeparator)
+
+	statementDay, err := strconv.Atoi(strings.TrimSpace(day))
+	if err != nil || statementDay < 1 || statementDay > lib.DaysInMonth {
+		return fmt.Errorf("%v: %v", FP.T["CreditCardInvalidStatementDay"], s)
+	}
+
+	due := 0
+	if hasDueDays {
+		due, err = strconv.Atoi(strings.TrimSpace(dueDays))
+		if err != nil || due < 1 {
+			return fmt.Errorf("%v: %v", FP.T["CreditCardInvalidDueDays"], s)
+		}
+	}
+
+	a.Type = AccountTypeCreditCard
+	a.StatementDay = statementDay
+	a.DueDays = due
+	a.FundingAccount = strings.TrimSpace(funding)
+
+	return nil
+}
@@ -0,0 +1,355 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	lib "github.com/charles-m-knox/finance-planner-lib"
+)
+
+// ResultRecord is the serializable form of a single lib.Result, used when
+// writing results out as JSON. Amounts are in cents, just like the amounts
+// stored in the config.
+type ResultRecord struct {
+	Date               string   `json:"date"`
+	Balance            int      `json:"balance"`
+	CumulativeIncome   int      `json:"cumulativeIncome"`
+	CumulativeExpenses int      `json:"cumulativeExpenses"`
+	DayExpenses        int      `json:"dayExpenses"`
+	DayIncome          int      `json:"dayIncome"`
+	DayNet             int      `json:"dayNet"`
+	DiffFromStart      int      `json:"diffFromStart"`
+	DayTransactions    []string `json:"dayTransactions"`
+	// the balance of each account by name, if the profile has accounts
+	Accounts map[string]int `json:"accounts,omitempty"`
+	// the remaining principal of each loan by name, as a negative balance
+	Loans map[string]int `json:"loans,omitempty"`
+	// the net worth, if the profile has investments or loans
+	NetWorth *int `json:"netWorth,omitempty"`
+	// the Monte Carlo projection, if the profile has simulations
+	MonteCarlo *MonteCarloDay `json:"monteCarlo,omitempty"`
+}
+
+// getBalancesByName returns the i'th day's balances by the name that they are
+// shown with, or nil if there are none.
+func getBalancesByName(balances AccountBalances, i int, name func(string) string) map[string]int {
+	var m map[string]int
+
+	for j, balance := range balances.GetBalances(i) {
+		if m == nil {
+			m = make(map[string]int)
+		}
+
+		m[name(balances.Names[j])] = balance
+	}
+
+	return m
+}
+
+// getResultRecord converts the i'th lib.Result into its serializable form,
+// along with the balance of each account and loan, the net worth and the Monte
+// Carlo projection on the same day.
+func getResultRecord(r lib.Result, extra ResultsExtra, i int) ResultRecord {
+	names := r.DayTransactionNamesSlice
+	if names == nil {
+		names = []string{}
+	}
+
+	var netWorth *int
+	if n, ok := extra.GetNetWorth(i); ok {
+		netWorth = &n
+	}
+
+	var monteCarlo *MonteCarloDay
+	if day, ok := extra.GetMonteCarlo(i); ok {
+		monteCarlo = &day
+	}
+
+	return ResultRecord{
+		Date:               lib.GetNowDateString(r.Date),
+		Balance:            r.Balance,
+		CumulativeIncome:   r.CumulativeIncome,
+		CumulativeExpenses: r.CumulativeExpenses,
+		DayExpenses:        r.DayExpenses,
+		DayIncome:          r.DayIncome,
+		DayNet:             r.DayNet,
+		DiffFromStart:      r.DiffFromStart,
+		DayTransactions:    names,
+		Accounts:           getBalancesByName(extra.Accounts, i, getAccountName),
+		Loans:              getBalancesByName(extra.Loans, i, func(name string) string { return name }),
+		NetWorth:           netWorth,
+		MonteCarlo:         monteCarlo,
+	}
+}
+
+// getResultsExportHeaders returns the translated column names, in the same
+// order as the results table, without any color formatting.
+func getResultsExportHeaders(extra ResultsExtra) []string {
+	headers := []string{FP.T["ResultsColumnDate"]}
+
+	for _, name := range extra.Accounts.Names {
+		headers = append(headers, getAccountName(name))
+	}
+
+	headers = append(headers, getResultsBalanceHeader(extra.Accounts))
+
+	for _, name := range extra.Loans.Names {
+		headers = append(headers, getLoanColumnName(name))
+	}
+
+	headers = append(headers,
+		FP.T["ResultsColumnCumulativeIncome"],
+		FP.T["ResultsColumnCumulativeExpenses"],
+		FP.T["ResultsColumnDayExpenses"],
+		FP.T["ResultsColumnDayIncome"],
+		FP.T["ResultsColumnDayNet"],
+		FP.T["ResultsColumnDiffFromStart"],
+	)
+
+	if extra.NetWorth != nil {
+		headers = append(headers, FP.T["ResultsColumnNetWorth"])
+	}
+
+	if extra.MonteCarlo != nil {
+		headers = append(headers,
+			FP.T["ResultsColumnP10"],
+			FP.T["ResultsColumnP50"],
+			FP.T["ResultsColumnP90"],
+			FP.T["ResultsColumnNegativeProbability"],
+		)
+	}
+
+	return append(headers, FP.T["ResultsColumnDayTransactionNames"])
+}
+
+// getResultsExportRow returns the plain-text values for the i'th result, the
+// balance of each account, the remaining principal of each loan, the net worth
+// and the Monte Carlo projection on the same day, in the same order as
+// getResultsExportHeaders.
+func getResultsExportRow(r lib.Result, extra ResultsExtra, i int) []string {
+	row := []string{lib.GetNowDateString(r.Date)}
+
+	for _, balance := range extra.Accounts.GetBalances(i) {
+		row = append(row, lib.FormatAsCurrency(balance))
+	}
+
+	row = append(row, lib.FormatAsCurrency(r.Balance))
+
+	for _, loan := range extra.Loans.GetBalances(i) {
+		row = append(row, lib.FormatAsCurrency(loan))
+	}
+
+	row = append(row,
+		lib.FormatAsCurrency(r.CumulativeIncome),
+		lib.FormatAsCurrency(r.CumulativeExpenses),
+		lib.FormatAsCurrency(r.DayExpenses),
+		lib.FormatAsCurrency(r.DayIncome),
+		lib.FormatAsCurrency(r.DayNet),
+		lib.FormatAsCurrency(r.DiffFromStart),
+	)
+
+	if netWorth, ok := extra.GetNetWorth(i); ok {
+		row = append(row, lib.FormatAsCurrency(netWorth))
+	}
+
+	if day, ok := extra.GetMonteCarlo(i); ok {
+		row = append(row,
+			lib.FormatAsCurrency(day.P10),
+			lib.FormatAsCurrency(day.P50),
+			lib.FormatAsCurrency(day.P90),
+			formatProbability(day.NegativeProbability),
+		)
+	}
+
+	return append(row, strings.Join(r.DayTransactionNamesSlice, "; "))
+}
+
+// writeResultsTable writes the results as a plain, space-aligned table that
+// is meant to be read in a terminal.
+func writeResultsTable(w io.Writer, results []lib.Result, extra ResultsExtra) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	_, err := fmt.Fprintln(tw, strings.Join(getResultsExportHeaders(extra), "\t"))
+	if err != nil {
+		return fmt.Errorf("failed to write table headers: %w", err)
+	}
+
+	for i := range results {
+		_, err = fmt.Fprintln(tw, strings.Join(getResultsExportRow(results[i], extra, i), "\t"))
+		if err != nil {
+			return fmt.Errorf("failed to write table row %v: %w", i, err)
+		}
+	}
+
+	err = tw.Flush()
+	if err != nil {
+		return fmt.Errorf("failed to flush table: %w", err)
+	}
+
+	return nil
+}
+
+// writeResultsCSV writes the results as CSV, including a header row.
+func writeResultsCSV(w io.Writer, results []lib.Result, extra ResultsExtra) error {
+	cw := csv.NewWriter(w)
+
+	err := cw.Write(getResultsExportHeaders(extra))
+	if err != nil {
+		return fmt.Errorf("failed to write csv headers: %w", err)
+	}
+
+	for i := range results {
+		err = cw.Write(getResultsExportRow(results[i], extra, i))
+		if err != nil {
+			return fmt.Errorf("failed to write csv row %v: %w", i, err)
+		}
+	}
+
+	cw.Flush()
+
+	err = cw.Error()
+	if err != nil {
+		return fmt.Errorf("failed to flush csv: %w", err)
+	}
+
+	return nil
+}
+
+// writeResultsJSON writes the results as an indented JSON array of
+// ResultRecord values.
+func writeResultsJSON(w io.Writer, results []lib.Result, extra ResultsExtra) error {
+	records := make([]ResultRecord, len(results))
+	for i := range results {
+		records[i] = getResultRecord(results[i], extra, i)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	err := enc.Encode(records)
+	if err != nil {
+		return fmt.Errorf("failed to encode json: %w", err)
+	}
+
+	return nil
+}
+
+// escapeMarkdownCell makes a value safe to put inside a markdown table cell.
+func escapeMarkdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", "\\|")
+}
+
+// writeMarkdownRow writes a single markdown table row.
+func writeMarkdownRow(w io.Writer, cells []string) error {
+	escaped := make([]string, len(cells))
+	for i := range cells {
+		escaped[i] = escapeMarkdownCell(cells[i])
+	}
+
+	_, err := fmt.Fprintf(w, "| %v |\n", strings.Join(escaped, " | "))
+	if err != nil {
+		return fmt.Errorf("failed to write markdown row: %w", err)
+	}
+
+	return nil
+}
+
+// writeResultsMarkdown writes the results as a markdown table, which renders
+// nicely in most reports and issue trackers.
+func writeResultsMarkdown(w io.Writer, results []lib.Result, extra ResultsExtra) error {
+	headers := getResultsExportHeaders(extra)
+
+	err := writeMarkdownRow(w, headers)
+	if err != nil {
+		return err
+	}
+
+	separators := make([]string, len(headers))
+	for i := range separators {
+		separators[i] = "---"
+	}
+
+	err = writeMarkdownRow(w, separators)
+	if err != nil {
+		return err
+	}
+
+	for i := range results {
+		err = writeMarkdownRow(w, getResultsExportRow(results[i], extra, i))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeResults writes the results to w in the requested format, which must be
+// one of the Format* constants.
+func writeResults(w io.Writer, results []lib.Result, extra ResultsExtra, format string) error {
+	switch strings.ToLower(format) {
+	case FormatTable:
+		return writeResultsTable(w, results, extra)
+	case FormatCSV:
+		return writeResultsCSV(w, results, extra)
+	case FormatJSON:
+		return writeResultsJSON(w, results, extra)
+	case FormatMarkdown:
+		return writeResultsMarkdown(w, results, extra)
+	default:
+		return fmt.Errorf("%v: %v", FP.T["ExportUnsupportedFormat"], format)
+	}
+}
+
+// getFormatFromPath determines the output format based on the extension of
+// the provided file path. Returns an empty string if the extension is not
+// recognized.
+func getFormatFromPath(file string) string {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".csv":
+		return FormatCSV
+	case ".json":
+		return FormatJSON
+	case ".md", ".markdown":
+		return FormatMarkdown
+	case ".txt":
+		return FormatTable
+	default:
+		return ""
+	}
+}
+
+// exportResults writes the results to the file at the provided path, using
+// the file's extension to determine the format. The file is overwritten if it
+// already exists.
+func exportResults(file string, results []lib.Result, extra ResultsExtra) error {
+	format := getFormatFromPath(file)
+	if format == "" {
+		return fmt.Errorf("%v: %v", FP.T["ExportUnsupportedFormat"], filepath.Ext(file))
+	}
+
+	f, err := os.Create(file)
+	if err != nil {
+		return fmt.Errorf("failed to create %v: %w", file, err)
+	}
+
+	err = writeResults(f, results, extra, format)
+	if err != nil {
+		f.Close()
+
+		return err
+	}
+
+	err = f.Close()
+	if err != nil {
+		return fmt.Errorf("failed to close %v: %w", file, err)
+	}
+
+	return nil
+}
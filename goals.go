@@ -0,0 +1,375 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	lib "github.com/charles-m-knox/finance-planner-lib"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// This file contains savings goals, which are amounts that a profile wants its
+// balance, or one of its accounts, to reach, optionally by a deadline. The
+// results show the day that each goal is reached, and for goals that miss
+// their deadline, how much more would need to be saved every month to hit it.
+// Goals are edited in the goals table on the profiles page.
+
+const (
+	// The height of the goals table on the profiles page, including its
+	// borders and headers, which leaves room for a handful of goals before it
+	// scrolls.
+	GoalsTableHeight = 7
+
+	// The target of a new goal, in cents.
+	DefaultGoalTarget = 100000
+)
+
+// Goal is an amount that the profile's balance should reach.
+type Goal struct {
+	Name string `yaml:"name"`
+	// the balance to reach, in cents
+	Target int `yaml:"target"`
+	// the date by which the target should be reached, formatted as
+	// YYYY-MM-DD; empty for none
+	Deadline string `yaml:"deadline,omitempty"`
+	// the account whose balance should reach the target; empty for the total
+	// balance of the results
+	Account string `yaml:"account,omitempty"`
+}
+
+// GoalProgress is how a goal fares in the results.
+type GoalProgress struct {
+	// the index of the first day of the results on which the goal is reached,
+	// or -1 if it never is
+	Reached int
+	// the index of the goal's deadline in the results, or -1 if the goal has
+	// no deadline or its deadline is outside of the results
+	Deadline int
+	// true if the goal is not reached by its deadline
+	Missed bool
+	// true if the goal's deadline is outside of the results, so they can't
+	// tell whether it is missed: either the deadline is before the results,
+	// or it is after them and the goal isn't reached by their last day
+	Outside bool
+	// how much more, in cents, would need to be saved every month from the
+	// start of the results to reach the goal by its deadline, if it is missed
+	Shortfall int
+}
+
+// getGoalBalance returns the balance that the goal is compared against at the
+// end of the i'th day of the results.
+func getGoalBalance(goal *Goal, results []lib.Result, extra *ResultsExtra, i int) int {
+	if goal.Account == "" {
+		return results[i].Balance
+	}
+
+	j := slices.Index(extra.Accounts.Names, goal.Account)
+
+	balances := extra.Accounts.GetBalances(i)
+	if j < 0 || j >= len(balances) {
+		return 0
+	}
+
+	return balances[j]
+}
+
+// getMonthsBetween returns the number of whole months from start until end,
+// which is at least 1.
+func getMonthsBetween(start, end time.Time) int {
+	months := (end.Year()-start.Year())*12 + int(end.Month()) - int(start.Month())
+	if end.Day() < start.Day() {
+		months--
+	}
+
+	return max(months, 1)
+}
+
+// getGoalsProgress returns the progress of each of the profile's goals in the
+// results. The monthly shortfall doesn't account for interest that the extra
+// savings would earn.
+func getGoalsProgress(p *Profile, results []lib.Result, extra *ResultsExtra) []GoalProgress {
+	if len(p.Goals) == 0 || len(results) == 0 {
+		return nil
+	}
+
+	days := make(map[string]int, len(results))
+	for i := range results {
+		days[lib.GetNowDateString(results[i].Date)] = i
+	}
+
+	progress := make([]GoalProgress, len(p.Goals))
+
+	for g := range p.Goals {
+		goal := &p.Goals[g]
+		progress[g] = GoalProgress{Reached: -1, Deadline: -1}
+
+		for i := range results {
+			if getGoalBalance(goal, results, extra, i) >= goal.Target {
+				progress[g].Reached = i
+
+				break
+			}
+		}
+
+		if goal.Deadline == "" {
+			continue
+		}
+
+		deadline, ok := days[goal.Deadline]
+		if !ok {
+			// deadlines are validated when they are edited, so this only
+			// skips hand-edited ones that can't be parsed
+			d, err := time.Parse(time.DateOnly, goal.Deadline)
+			progress[g].Outside = err == nil && (d.Before(results[0].Date) || progress[g].Reached < 0)
+
+			continue
+		}
+
+		progress[g].Deadline = deadline
+
+		reached := progress[g].Reached
+		if reached >= 0 && reached <= deadline {
+			continue
+		}
+
+		months := getMonthsBetween(results[0].Date, results[deadline].Date)
+		gap := goal.Target - getGoalBalance(goal, results, extra, deadline)
+
+		progress[g].Missed = true
+		progress[g].Shortfall = (gap + months - 1) / months
+	}
+
+	return progress
+}
+
+// getGoalsStats returns the progress of each of the profile's goals as text,
+// to be shown after the other statistics about the results.
+func getGoalsStats(p *Profile, results []lib.Result, progress []GoalProgress) string {
+	var sb strings.Builder
+
+	for g := range progress {
+		if g >= len(p.Goals) {
+			break
+		}
+
+		goal := &p.Goals[g]
+
+		sb.WriteString(fmt.Sprintf("%v %v (%v", FP.T["ResultsStatsGoal"], tview.Escape(goal.Name), lib.FormatAsCurrency(goal.Target)))
+
+		if goal.Account != "" {
+			sb.WriteString(fmt.Sprintf(" %v %v", FP.T["ResultsStatsGoalIn"], tview.Escape(goal.Account)))
+		}
+
+		if goal.Deadline != "" {
+			sb.WriteString(fmt.Sprintf(" %v %v", FP.T["ResultsStatsGoalBy"], goal.Deadline))
+		}
+
+		if progress[g].Reached >= 0 {
+			sb.WriteString(fmt.Sprintf("): %v %v", FP.T["ResultsStatsGoalReached"], getBalanceAlertDate(results, progress[g].Reached)))
+		} else {
+			sb.WriteString(fmt.Sprintf("): %v", FP.T["ResultsStatsGoalNotReached"]))
+		}
+
+		if progress[g].Missed {
+			sb.WriteString(fmt.Sprintf("; %v%v %v %v%v",
+				FP.Colors["ResultsDescriptionError"],
+				FP.T["ResultsStatsGoalMissed"],
+				lib.FormatAsCurrency(progress[g].Shortfall),
+				FP.T["ResultsStatsGoalPerMonth"],
+				FP.Colors["ResultsDescriptionStats"],
+			))
+		}
+
+		if progress[g].Outside {
+			outside := FP.T["ResultsStatsGoalDeadlineAfterResults"]
+			if goal.Deadline < lib.GetNowDateString(results[0].Date) {
+				outside = FP.T["ResultsStatsGoalDeadlineBeforeResults"]
+			}
+
+			sb.WriteString(fmt.Sprintf("; %v%v%v", FP.Colors["ResultsDescriptionError"], outside, FP.Colors["ResultsDescriptionStats"]))
+		}
+
+		sb.WriteString("\n")
+	}
+
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// getGoalsTableHeaders returns the columns of the goals table.
+func getGoalsTableHeaders() []TableCell {
+	return []TableCell{
+		{Text: FP.T["GoalsColumnName"], Color: FP.Colors["GoalsColumnName"]},
+		{Text: FP.T["GoalsColumnTarget"], Color: FP.Colors["GoalsColumnTarget"]},
+		{Text: FP.T["GoalsColumnDeadline"], Color: FP.Colors["GoalsColumnDeadline"]},
+		{Text: FP.T["GoalsColumnAccount"], Color: FP.Colors["GoalsColumnAccount"], Expand: 1},
+	}
+}
+
+// getGoalsTableCells returns the cells of a goal's row in the goals table.
+func getGoalsTableCells(goal *Goal) []TableCell {
+	account := FP.T["GoalsTotalBalance"]
+	if goal.Account != "" {
+		account = tview.Escape(goal.Account)
+	}
+
+	return []TableCell{
+		{Text: tview.Escape(goal.Name), Color: FP.Colors["GoalsColumnName"]},
+		{Text: lib.FormatAsCurrency(goal.Target), Color: FP.Colors["GoalsColumnTarget"]},
+		{Text: goal.Deadline, Color: FP.Colors["GoalsColumnDeadline"]},
+		{Text: account, Color: FP.Colors["GoalsColumnAccount"], Expand: 1},
+	}
+}
+
+// setGoalsTableRow sets the cells of the i'th row of the goals table. The
+// headers can't be selected, since there is nothing to edit in them.
+func setGoalsTableRow(i int, cells []TableCell) {
+	for j := range cells {
+		cell := tview.NewTableCell(fmt.Sprintf("%v%v%v", cells[j].Color, cells[j].Text, Reset)).
+			SetSelectable(i > 0)
+		if cells[j].Expand > 0 {
+			cell.SetExpansion(cells[j].Expand)
+		}
+
+		FP.GoalsTable.SetCell(i, j, cell)
+	}
+}
+
+// getGoalsTable clears and re-populates the goals table with the goals of the
+// currently selected profile.
+func getGoalsTable() {
+	if FP.GoalsTable == nil {
+		return
+	}
+
+	FP.GoalsTable.Clear()
+
+	setGoalsTableRow(0, getGoalsTableHeaders())
+
+	if FP.SelectedProfile == nil {
+		return
+	}
+
+	for i := range FP.SelectedProfile.Goals {
+		setGoalsTableRow(i+1, getGoalsTableCells(&FP.SelectedProfile.Goals[i]))
+	}
+}
+
+// addGoal appends a new goal to the selected profile and selects it.
+func addGoal() {
+	FP.SelectedProfile.Goals = append(FP.SelectedProfile.Goals, Goal{
+		Name:   FP.T["GoalDefaultName"],
+		Target: DefaultGoalTarget,
+	})
+
+	modified()
+	getGoalsTable()
+
+	_, cc := FP.GoalsTable.GetSelection()
+	FP.GoalsTable.Select(len(FP.SelectedProfile.Goals), cc)
+}
+
+// deleteGoal removes the goal in the goals table's selected row.
+func deleteGoal() {
+	cr, cc := FP.GoalsTable.GetSelection()
+	i := cr - 1 // skip header
+
+	if i < 0 || i >= len(FP.SelectedProfile.Goals) {
+		return
+	}
+
+	FP.SelectedProfile.Goals = slices.Delete(FP.SelectedProfile.Goals, i, i+1)
+
+	modified()
+	getGoalsTable()
+	FP.GoalsTable.Select(min(cr, len(FP.SelectedProfile.Goals)), cc)
+}
+
+func goalSetName(i int, name string) bool {
+	FP.SelectedProfile.Goals[i].Name = name
+
+	return true
+}
+
+func goalSetTarget(i int, target string) bool {
+	t := int(lib.ParseDollarAmount(target, true))
+	if t <= 0 {
+		activateTransactionsInputFieldNoAutocompleteReset(fmt.Sprintf("%v:", FP.T["GoalInvalidTarget"]), target)
+
+		return false
+	}
+
+	FP.SelectedProfile.Goals[i].Target = t
+
+	return true
+}
+
+func goalSetDeadline(i int, deadline string) bool {
+	deadline = strings.TrimSpace(deadline)
+
+	if deadline != "" {
+		if _, err := time.Parse(time.DateOnly, deadline); err != nil {
+			activateTransactionsInputFieldNoAutocompleteReset(fmt.Sprintf("%v:", FP.T["GoalInvalidDeadline"]), deadline)
+
+			return false
+		}
+	}
+
+	FP.SelectedProfile.Goals[i].Deadline = deadline
+
+	return true
+}
+
+func goalSetAccount(i int, account string) bool {
+	account = strings.TrimSpace(account)
+
+	if err := validateAccount(FP.SelectedProfile, account); err != nil {
+		activateTransactionsInputFieldNoAutocompleteReset(fmt.Sprintf("%v:", tview.Escape(err.Error())), account)
+
+		return false
+	}
+
+	FP.SelectedProfile.Goals[i].Account = account
+
+	return true
+}
+
+// goalsTableSelectedFunc prompts for a new value of the selected cell of the
+// goals table.
+func goalsTableSelectedFunc(row, column int) {
+	i := row - 1 // skip header
+	if i < 0 || i >= len(FP.SelectedProfile.Goals) {
+		return
+	}
+
+	goal := &FP.SelectedProfile.Goals[i]
+
+	var (
+		f     func(ii int, newVal string) bool
+		label string
+		value string
+	)
+
+	switch getGoalsTableHeaders()[column].Text {
+	case FP.T["GoalsColumnName"]:
+		f, label, value = goalSetName, FP.T["GoalsInputFieldEditNameLabel"], goal.Name
+	case FP.T["GoalsColumnTarget"]:
+		f, label, value = goalSetTarget, FP.T["GoalsInputFieldEditTargetLabel"], lib.FormatAsCurrency(goal.Target)
+	case FP.T["GoalsColumnDeadline"]:
+		f, label, value = goalSetDeadline, FP.T["GoalsInputFieldEditDeadlineLabel"], goal.Deadline
+	case FP.T["GoalsColumnAccount"]:
+		f, label, value = goalSetAccount, FP.T["GoalsInputFieldEditAccountLabel"], goal.Account
+	default:
+		return
+	}
+
+	FP.TransactionsInputField.SetDoneFunc(func(key tcell.Key) {
+		txChangeDoneFunc(i, f)(key)
+		getGoalsTable()
+	})
+
+	activateTransactionsInputField(fmt.Sprintf("%v:", label), value)
+}
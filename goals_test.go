@@ -0,0 +1,79 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetGoalsProgress(t *testing.T) {
+	// 90 days from 2025-01-01 until 2025-03-31, on which the balance grows by
+	// $10.00 a day, from $0.00
+	results := getTestResults(time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC), 90, 0)
+	for i := range results {
+		results[i].Balance = i * 1000
+	}
+
+	tests := []struct {
+		name string
+		goal Goal
+		want GoalProgress
+	}{
+		{
+			name: "no deadline",
+			goal: Goal{Target: 10000},
+			want: GoalProgress{Reached: 10, Deadline: -1},
+		},
+		{
+			name: "never reached without a deadline",
+			goal: Goal{Target: 1000000},
+			want: GoalProgress{Reached: -1, Deadline: -1},
+		},
+		{
+			name: "reached by its deadline",
+			goal: Goal{Target: 10000, Deadline: "2025-01-11"},
+			want: GoalProgress{Reached: 10, Deadline: 10},
+		},
+		{
+			name: "reached after its deadline",
+			goal: Goal{Target: 50000, Deadline: "2025-02-01"},
+			// $190.00 short after 1 month
+			want: GoalProgress{Reached: 50, Deadline: 31, Missed: true, Shortfall: 19000},
+		},
+		{
+			name: "never reached by its deadline",
+			goal: Goal{Target: 200000, Deadline: "2025-03-01"},
+			// $1,410.00 short after 2 months
+			want: GoalProgress{Reached: -1, Deadline: 59, Missed: true, Shortfall: 70500},
+		},
+		{
+			name: "deadline before the results",
+			goal: Goal{Target: 10000, Deadline: "2024-12-31"},
+			want: GoalProgress{Reached: 10, Deadline: -1, Outside: true},
+		},
+		{
+			name: "deadline after the results and reached",
+			goal: Goal{Target: 10000, Deadline: "2025-12-31"},
+			want: GoalProgress{Reached: 10, Deadline: -1},
+		},
+		{
+			name: "deadline after the results and not reached",
+			goal: Goal{Target: 200000, Deadline: "2025-12-31"},
+			want: GoalProgress{Reached: -1, Deadline: -1, Outside: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Profile{Goals: []Goal{tt.goal}}
+
+			progress := getGoalsProgress(&p, results, &ResultsExtra{})
+			if len(progress) != 1 {
+				t.Fatalf("got %v goals, want 1", len(progress))
+			}
+
+			if progress[0] != tt.want {
+				t.Errorf("got %+v, want %+v", progress[0], tt.want)
+			}
+		})
+	}
+}
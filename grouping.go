@@ -0,0 +1,356 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	lib "github.com/charles-m-knox/finance-planner-lib"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// This file contains the grouped views of the results table, which summarize
+// the results by week, month, quarter or year instead of showing one row per
+// day. A grouped row can be drilled down into, which shows the daily rows
+// starting at the first day of its period, with the period's days
+// highlighted, and escaping from there goes back to the grouped view.
+
+const (
+	ResultsGroupingDay     = "DAY"
+	ResultsGroupingWeek    = "WEEK"
+	ResultsGroupingMonth   = "MONTH"
+	ResultsGroupingQuarter = "QUARTER"
+	ResultsGroupingYear    = "YEAR"
+)
+
+// ResultsGroupings are the ways that the results table can be grouped, in the
+// order that the group action cycles through them.
+var ResultsGroupings = []string{
+	ResultsGroupingDay,
+	ResultsGroupingWeek,
+	ResultsGroupingMonth,
+	ResultsGroupingQuarter,
+	ResultsGroupingYear,
+}
+
+// ResultsGroup summarizes the days of the results that fall within the same
+// period, such as a month.
+type ResultsGroup struct {
+	// the name of the period, such as "2025-03" or "2025-Q1"
+	Label string
+	// the indexes of the first and last days of the period in the results
+	First int
+	Last  int
+	// the balance before the first day and after the last day
+	Opening int
+	Closing int
+	// the totals of the days' income, expenses and net
+	Income   int
+	Expenses int
+	Net      int
+	// the lowest balance at the end of any of the days
+	Lowest int
+}
+
+// isResultsGrouped returns true if the results table shows grouped rows
+// instead of one row per day.
+func isResultsGrouped() bool {
+	return FP.ResultsGrouping != ResultsGroupingDay
+}
+
+// getNextResultsGrouping returns the grouping that comes after the given one
+// in ResultsGroupings.
+func getNextResultsGrouping(grouping string) string {
+	i := slices.Index(ResultsGroupings, grouping)
+
+	return ResultsGroupings[(i+1)%len(ResultsGroupings)]
+}
+
+// getResultsGroupingName returns the name of the grouping as it is shown in
+// the results table's title, such as "by month".
+func getResultsGroupingName(grouping string) string {
+	switch grouping {
+	case ResultsGroupingWeek:
+		return FP.T["ResultsGroupingWeek"]
+	case ResultsGroupingMonth:
+		return FP.T["ResultsGroupingMonth"]
+	case ResultsGroupingQuarter:
+		return FP.T["ResultsGroupingQuarter"]
+	case ResultsGroupingYear:
+		return FP.T["ResultsGroupingYear"]
+	}
+
+	return FP.T["ResultsGroupingDay"]
+}
+
+// getResultsGroupLabel returns the name of the period that the date falls
+// within. Weeks are ISO 8601 weeks, which start on Mondays.
+func getResultsGroupLabel(t time.Time, grouping string) string {
+	switch grouping {
+	case ResultsGroupingWeek:
+		year, week := t.ISOWeek()
+
+		return fmt.Sprintf("%04d-W%02d", year, week)
+	case ResultsGroupingMonth:
+		return t.Format("2006-01")
+	case ResultsGroupingQuarter:
+		return fmt.Sprintf("%04d-Q%d", t.Year(), (int(t.Month())-1)/3+1)
+	case ResultsGroupingYear:
+		return t.Format("2006")
+	}
+
+	return lib.GetNowDateString(t)
+}
+
+// getResultsGroups groups consecutive days of the results by the period that
+// they fall within.
+func getResultsGroups(results []lib.Result, grouping string) []ResultsGroup {
+	groups := []ResultsGroup{}
+
+	for i := range results {
+		r := &results[i]
+		label := getResultsGroupLabel(r.Date, grouping)
+
+		if len(groups) == 0 || groups[len(groups)-1].Label != label {
+			groups = append(groups, ResultsGroup{
+				Label:   label,
+				First:   i,
+				Opening: r.Balance - r.DayNet,
+				Lowest:  r.Balance,
+			})
+		}
+
+		g := &groups[len(groups)-1]
+		g.Last = i
+		g.Closing = r.Balance
+		g.Income += r.DayIncome
+		g.Expenses += r.DayExpenses
+		g.Net += r.DayNet
+		g.Lowest = min(g.Lowest, r.Balance)
+	}
+
+	return groups
+}
+
+// getResultsGroupsTableHeaders returns the columns of the grouped results
+// table.
+func getResultsGroupsTableHeaders() []TableCell {
+	return []TableCell{
+		{Text: FP.T["ResultsColumnPeriod"], Color: FP.Colors["ResultsColumnDate"]},
+		{Text: FP.T["ResultsColumnOpeningBalance"], Color: FP.Colors["ResultsColumnBalance"]},
+		{Text: FP.T["ResultsColumnClosingBalance"], Color: FP.Colors["ResultsColumnBalance"]},
+		{Text: FP.T["ResultsColumnIncome"], Color: FP.Colors["ResultsColumnDayIncome"]},
+		{Text: FP.T["ResultsColumnExpenses"], Color: FP.Colors["ResultsColumnDayExpenses"]},
+		{Text: FP.T["ResultsColumnNet"], Color: FP.Colors["ResultsColumnDayNet"], Expand: 1},
+	}
+}
+
+// getResultsGroupCells returns the cells of a group's row in the grouped
+// results table.
+func getResultsGroupCells(g *ResultsGroup) []TableCell {
+	return []TableCell{
+		{Text: g.Label, Color: FP.Colors["ResultsColumnDate"]},
+		{Text: lib.FormatAsCurrency(g.Opening), Color: FP.Colors["ResultsColumnBalance"]},
+		{Text: lib.FormatAsCurrency(g.Closing), Color: FP.Colors["ResultsColumnBalance"]},
+		{Text: lib.FormatAsCurrency(g.Income), Color: FP.Colors["ResultsColumnDayIncome"]},
+		{Text: lib.FormatAsCurrency(g.Expenses), Color: FP.Colors["ResultsColumnDayExpenses"]},
+		{Text: lib.FormatAsCurrency(g.Net), Color: FP.Colors["ResultsColumnDayNet"], Expand: 1},
+	}
+}
+
+// setResultsTableRow sets the cells of the i'th row of the results table.
+func setResultsTableRow(i int, cells []TableCell) {
+	for j := range cells {
+		cell := tview.NewTableCell(fmt.Sprintf("%v%v%v", cells[j].Color, cells[j].Text, Reset))
+		if cells[j].Expand > 0 {
+			cell.SetExpansion(cells[j].Expand)
+		}
+
+		FP.ResultsTable.SetCell(i, j, cell)
+	}
+}
+
+// setResultsTableRows clears and re-populates the results table from the
+// latest results, either with one row per day or with one row per group,
+// depending on FP.ResultsGrouping. The results are not recalculated.
+func setResultsTableRows() {
+	FP.ResultsTable.Clear()
+	FP.ResultsGroups = nil
+
+	title := FP.T["ResultsTableTitle"]
+
+	if FP.LatestResults == nil {
+		FP.ResultsTable.SetTitle(title)
+
+		return
+	}
+
+	results := *(FP.LatestResults)
+
+	if isResultsGrouped() {
+		FP.ResultsTable.SetTitle(fmt.Sprintf("%v (%v)", title, getResultsGroupingName(FP.ResultsGrouping)))
+		FP.ResultsGroups = getResultsGroups(results, FP.ResultsGrouping)
+
+		setResultsTableRow(0, getResultsGroupsTableHeaders())
+
+		for i := range FP.ResultsGroups {
+			setResultsTableRow(i+1, getResultsGroupCells(&FP.ResultsGroups[i]))
+		}
+	} else {
+		if FP.ResultsDrillDownFrom != "" {
+			title = fmt.Sprintf("%v (%v)", title, FP.ResultsDrillDownLabel)
+		}
+
+		FP.ResultsTable.SetTitle(title)
+
+		setResultsTableHeaders(FP.LatestResultsExtra)
+
+		for i := range results {
+			setResultsTableCellsForResult(i+1, results[i], FP.LatestResultsExtra, i)
+		}
+	}
+
+	applyResultsSearch()
+}
+
+// getResultsGroupRow returns the row of the results table that shows the i'th
+// day of the results, which is the row of its group when the table is
+// grouped.
+func getResultsGroupRow(i int) int {
+	if !isResultsGrouped() {
+		return i + 1 // skip header
+	}
+
+	for g := range FP.ResultsGroups {
+		if FP.ResultsGroups[g].First <= i && i <= FP.ResultsGroups[g].Last {
+			return g + 1 // skip header
+		}
+	}
+
+	return 0
+}
+
+// getSelectedResultsDay returns the index in the results of the day that is
+// selected in the results table, which is the first day of the selected
+// group when the table is grouped.
+func getSelectedResultsDay() int {
+	row, _ := FP.ResultsTable.GetSelection()
+	if !isResultsGrouped() {
+		return row - 1 // skip header
+	}
+
+	if row < 1 || row > len(FP.ResultsGroups) {
+		return -1
+	}
+
+	return FP.ResultsGroups[row-1].First
+}
+
+// selectResultsDay selects the row of the results table that shows the i'th
+// day of the results.
+func selectResultsDay(i int) {
+	_, cc := FP.ResultsTable.GetSelection()
+	FP.ResultsTable.Select(max(getResultsGroupRow(i), 1), cc)
+}
+
+// setResultsGrouping changes how the results table is grouped, keeping the
+// selected day in view.
+func setResultsGrouping(grouping string) {
+	day := getSelectedResultsDay()
+
+	FP.ResultsGrouping = grouping
+
+	setResultsTableRows()
+	selectResultsDay(day)
+}
+
+// cycleResultsGrouping switches the results table to the next grouping in
+// ResultsGroupings.
+func cycleResultsGrouping() {
+	FP.ResultsDrillDownFrom = ""
+
+	setResultsGrouping(getNextResultsGrouping(FP.ResultsGrouping))
+}
+
+// drillDownResultsGroup shows the daily rows of the group in the given row of
+// the grouped results table, with its first day selected and at the top of
+// the table.
+func drillDownResultsGroup(row int) {
+	if !isResultsGrouped() || row < 1 || row > len(FP.ResultsGroups) {
+		return
+	}
+
+	g := FP.ResultsGroups[row-1]
+
+	FP.ResultsDrillDownFrom = FP.ResultsGrouping
+	FP.ResultsDrillDownLabel = g.Label
+	FP.ResultsGrouping = ResultsGroupingDay
+
+	setResultsTableRows()
+
+	_, cc := FP.ResultsTable.GetSelection()
+	FP.ResultsTable.Select(g.First+1, cc) // skip header
+	FP.ResultsTable.SetOffset(g.First, 0)
+}
+
+// isResultsDrillDownDay returns true if the i'th day of the results is in the
+// group that the results table was drilled down into.
+func isResultsDrillDownDay(results []lib.Result, i int) bool {
+	return FP.ResultsDrillDownFrom != "" &&
+		getResultsGroupLabel(results[i].Date, FP.ResultsDrillDownFrom) == FP.ResultsDrillDownLabel
+}
+
+// getResultsDayRowColor returns the background color of the i'th day's row in
+// the daily results table. Days with a low balance are highlighted as in
+// getResultsRowColor, and otherwise, the days of the group that the table
+// was drilled down into are highlighted.
+func getResultsDayRowColor(results []lib.Result, i int) tcell.Color {
+	bg := getResultsRowColor(FP.SelectedProfile, results[i].Balance)
+	if bg == tcell.ColorReset && isResultsDrillDownDay(results, i) {
+		return tcell.GetColor(FP.Colors["ResultsRowDrillDownColor"])
+	}
+
+	return bg
+}
+
+// drillUpResultsGroup goes back to the grouping that the results table was
+// drilled down from, if any. It returns false if it wasn't drilled down.
+func drillUpResultsGroup() bool {
+	if FP.ResultsDrillDownFrom == "" {
+		return false
+	}
+
+	grouping := FP.ResultsDrillDownFrom
+	FP.ResultsDrillDownFrom = ""
+
+	setResultsGrouping(grouping)
+
+	return true
+}
+
+// getResultsGroupDescription returns a description of the group for the
+// results description text view.
+func getResultsGroupDescription(g *ResultsGroup) string {
+	results := *(FP.LatestResults)
+
+	return fmt.Sprintf("%v%v: %v - %v (%v %v)\n%v: %v\n\n%v%v",
+		FP.Colors["ResultsDescriptionPassive"],
+		g.Label,
+		lib.GetNowDateString(results[g.First].Date),
+		lib.GetNowDateString(results[g.Last].Date),
+		g.Last-g.First+1,
+		FP.T["ResultsGroupDays"],
+		FP.T["ResultsStatsLowestBalance"],
+		lib.FormatAsCurrency(g.Lowest),
+		FP.T["ResultsGroupDrillDownHint"],
+		Reset,
+	)
+}
+
+// getResultsGroupRowColor returns the background color of a group's row,
+// which is highlighted in the same way as its lowest day would be.
+func getResultsGroupRowColor(g *ResultsGroup) tcell.Color {
+	return getResultsRowColor(FP.SelectedProfile, g.Lowest)
+}
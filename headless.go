@@ -0,0 +1,244 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	lib "github.com/charles-m-knox/finance-planner-lib"
+	"gopkg.in/yaml.v3"
+)
+
+// This file contains the subcommands that can be run from scripts, cron jobs,
+// etc. None of them start the tview application.
+
+var (
+	// Used when an unrecognized subcommand is given.
+	ErrUnknownCommand = errors.New("unknown command")
+
+	// Used when the merge command finishes, but had conflicts that should be
+	// reviewed.
+	ErrMergeConflicts = errors.New("merge had conflicts")
+
+	// Used when a required flag is not given.
+	ErrMissingFlag = errors.New("missing required flag")
+)
+
+// runCommand runs the subcommand named by the first value in args, passing
+// the remaining args to it. The config must already be loaded into FP.Config.
+func runCommand(args []string) error {
+	if len(args) == 0 {
+		return nil
+	}
+
+	switch args[0] {
+	case CommandResults:
+		return runResultsCommand(args[1:])
+	case CommandMerge:
+		return runMergeCommand(args[1:])
+	default:
+		return fmt.Errorf("%w: %v", ErrUnknownCommand, args[0])
+	}
+}
+
+// getProfileByName returns a pointer to the profile in conf with the provided
+// name. If name is empty, the first profile is returned. Returns nil if no
+// profile could be found.
+func getProfileByName(conf *Config, name string) *Profile {
+	if len(conf.Profiles) == 0 {
+		return nil
+	}
+
+	if name == "" {
+		return &(conf.Profiles[0])
+	}
+
+	for i := range conf.Profiles {
+		if conf.Profiles[i].Name == name {
+			return &(conf.Profiles[i])
+		}
+	}
+
+	return nil
+}
+
+// setProfileDateFromFlag parses a YYYY-MM-DD string and writes its year,
+// month and day into the provided profile fields. Does nothing when the value
+// is empty.
+func setProfileDateFromFlag(value string, y, m, d *string) error {
+	if value == "" {
+		return nil
+	}
+
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return fmt.Errorf("%v %v: %w", FP.T["CommandResultsInvalidDate"], value, err)
+	}
+
+	*y = strconv.Itoa(t.Year())
+	*m = strconv.Itoa(int(t.Month()))
+	*d = strconv.Itoa(t.Day())
+
+	return nil
+}
+
+// runResultsCommand calculates the results for a single profile and writes
+// them to stdout. Flags that are not provided fall back to the values saved in
+// the profile itself, and then to the same defaults as the results page.
+func runResultsCommand(args []string) error {
+	var profileName, start, end, balance, simulations, seed, format string
+
+	fs := flag.NewFlagSet(CommandResults, flag.ContinueOnError)
+	fs.StringVar(&profileName, FP.T["CommandResultsProfileFlag"], "", FP.T["CommandResultsProfileDesc"])
+	fs.StringVar(&start, FP.T["CommandResultsStartFlag"], "", FP.T["CommandResultsStartDesc"])
+	fs.StringVar(&end, FP.T["CommandResultsEndFlag"], "", FP.T["CommandResultsEndDesc"])
+	fs.StringVar(&balance, FP.T["CommandResultsBalanceFlag"], "", FP.T["CommandResultsBalanceDesc"])
+	fs.StringVar(&simulations, FP.T["CommandResultsSimulationsFlag"], "", FP.T["CommandResultsSimulationsDesc"])
+	fs.StringVar(&seed, FP.T["CommandResultsSeedFlag"], "", FP.T["CommandResultsSeedDesc"])
+	fs.StringVar(&format, FP.T["CommandResultsFormatFlag"], FormatTable, FP.T["CommandResultsFormatDesc"])
+
+	err := fs.Parse(args)
+	if err != nil {
+		return fmt.Errorf("failed to parse %v flags: %w", CommandResults, err)
+	}
+
+	selected := getProfileByName(&FP.Config, profileName)
+	if selected == nil {
+		return fmt.Errorf("%v: %v", FP.T["CommandResultsProfileNotFound"], profileName)
+	}
+
+	// work on a copy so that nothing here could ever leak back into the config
+	p := *selected
+
+	setProfileDefaults(&p)
+
+	err = setProfileDateFromFlag(start, &p.StartYear, &p.StartMonth, &p.StartDay)
+	if err != nil {
+		return err
+	}
+
+	err = setProfileDateFromFlag(end, &p.EndYear, &p.EndMonth, &p.EndDay)
+	if err != nil {
+		return err
+	}
+
+	if balance != "" {
+		p.StartingBalance = lib.FormatAsCurrency(int(lib.ParseDollarAmount(balance, true)))
+	}
+
+	if simulations != "" {
+		p.Simulations, err = strconv.Atoi(simulations)
+		if err != nil || p.Simulations < 0 || p.Simulations > MaxSimulations {
+			return fmt.Errorf("%v: %v", FP.T["CommandResultsInvalidSimulations"], simulations)
+		}
+	}
+
+	if seed != "" {
+		p.SimulationSeed, err = strconv.ParseInt(seed, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%v: %v", FP.T["CommandResultsInvalidSeed"], seed)
+		}
+	}
+
+	results, extra, err := calculateResults(&p, func(_ string) {})
+	if err != nil {
+		return err
+	}
+
+	return writeResults(os.Stdout, results, extra, format)
+}
+
+// loadMergeInput loads one of the configs for the merge command.
+func loadMergeInput(flagName, file string) (Config, error) {
+	if file == "" {
+		return Config{}, fmt.Errorf("%w: -%v", ErrMissingFlag, flagName)
+	}
+
+	conf, _, err := loadConfFrom(file, FP.T)
+	if err != nil {
+		return conf, err
+	}
+
+	processConfig(&conf)
+
+	return conf, nil
+}
+
+// runMergeCommand performs a three-way merge of two configs that diverged from
+// a common base, such as copies of a config in a synced folder. The merged
+// config is written to the output file (or stdout), and any conflicts are
+// written as a markdown report to the report file (or stderr). Conflicts are
+// resolved automatically as described in merge.go, but result in an error so
+// that scripts can tell that they need to be reviewed.
+func runMergeCommand(args []string) error {
+	var base, ours, theirs, output, report string
+
+	fs := flag.NewFlagSet(CommandMerge, flag.ContinueOnError)
+	fs.StringVar(&base, FP.T["CommandMergeBaseFlag"], "", FP.T["CommandMergeBaseDesc"])
+	fs.StringVar(&ours, FP.T["CommandMergeOursFlag"], "", FP.T["CommandMergeOursDesc"])
+	fs.StringVar(&theirs, FP.T["CommandMergeTheirsFlag"], "", FP.T["CommandMergeTheirsDesc"])
+	fs.StringVar(&output, FP.T["CommandMergeOutputFlag"], "", FP.T["CommandMergeOutputDesc"])
+	fs.StringVar(&report, FP.T["CommandMergeReportFlag"], "", FP.T["CommandMergeReportDesc"])
+
+	err := fs.Parse(args)
+	if err != nil {
+		return fmt.Errorf("failed to parse %v flags: %w", CommandMerge, err)
+	}
+
+	baseConf, err := loadMergeInput(FP.T["CommandMergeBaseFlag"], base)
+	if err != nil {
+		return err
+	}
+
+	ourConf, err := loadMergeInput(FP.T["CommandMergeOursFlag"], ours)
+	if err != nil {
+		return err
+	}
+
+	theirConf, err := loadMergeInput(FP.T["CommandMergeTheirsFlag"], theirs)
+	if err != nil {
+		return err
+	}
+
+	merged, conflicts := mergeConfigs(baseConf, ourConf, theirConf)
+
+	b, err := yaml.Marshal(merged)
+	if err != nil {
+		return fmt.Errorf("%v: %w", FP.T["SaveMarshalFailed"], err)
+	}
+
+	if output == "" {
+		_, err = os.Stdout.Write(b)
+	} else {
+		err = writeFileAtomic(output, b, 0o644)
+	}
+
+	if err != nil {
+		return fmt.Errorf("%v: %w", FP.T["CommandMergeWriteFailed"], err)
+	}
+
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	if report == "" {
+		err = writeMergeReport(os.Stderr, conflicts)
+	} else {
+		var rb strings.Builder
+
+		err = writeMergeReport(&rb, conflicts)
+		if err == nil {
+			err = writeFileAtomic(report, []byte(rb.String()), 0o644)
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return fmt.Errorf("%w: %v", ErrMergeConflicts, len(conflicts))
+}
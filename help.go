@@ -162,6 +162,7 @@ func setBottomPageNavText() {
 		{PageHelp, FP.T["BottomPageNavTextHelp"], getBinding(ActionGlobalHelp)},
 		{PageProfiles, FP.T["BottomPageNavTextProfiles"], getBinding(ActionProfiles)},
 		{PageResults, FP.T["BottomPageNavTextResults"], getBinding(ActionResults)},
+		{PageHistory, FP.T["BottomPageNavTextHistory"], getBinding(ActionHistory)},
 	}
 
 	var sb strings.Builder
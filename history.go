@@ -0,0 +1,365 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/gdamore/tcell/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// This file contains the logic for persisting the undo buffer across sessions.
+// The undo buffer's snapshots are written to a history file under the XDG
+// state directory, keyed by the absolute path of the config file, so that a
+// user can undo changes that were made in a previous session.
+
+// The version of the undo history file format. Files with any other version
+// are ignored.
+const UndoHistoryVersion = 1
+
+// Used when the undo history file fails any of its integrity checks.
+var ErrUndoHistoryCorrupt = errors.New("undo history is corrupt")
+
+// UndoHistory is the on-disk representation of the undo buffer.
+type UndoHistory struct {
+	Version int `json:"version"`
+
+	// The absolute path of the config file that this history belongs to.
+	ConfigFile string `json:"configFile"`
+
+	SavedAt time.Time `json:"savedAt"`
+
+	// Whether or not the snapshots are compressed via compress().
+	Compressed bool `json:"compressed"`
+
+	// The undo buffer position at the time the history was saved.
+	Pos int `json:"pos"`
+
+	Snapshots [][]byte `json:"snapshots"`
+
+	// The time at which each snapshot was taken, in the same order.
+	Times []time.Time `json:"times"`
+
+	// The hex-encoded sha256 sum of each snapshot, in the same order.
+	Checksums []string `json:"checksums"`
+}
+
+// getChecksum returns the hex-encoded sha256 sum of b.
+func getChecksum(b []byte) string {
+	sum := sha256.Sum256(b)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// getUndoHistoryFile returns the path of the history file for the provided
+// config file, creating its parent directories if necessary.
+func getUndoHistoryFile(configFile string) (string, string, error) {
+	abs, err := filepath.Abs(configFile)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get absolute path of %v: %w", configFile, err)
+	}
+
+	sum := sha256.Sum256([]byte(abs))
+	name := fmt.Sprintf("%v.json", hex.EncodeToString(sum[:8]))
+
+	file, err := xdg.StateFile(path.Join(DefaultConfigParentDir, "history", name))
+	if err != nil {
+		return "", abs, fmt.Errorf("failed to get undo history file path: %w", err)
+	}
+
+	return file, abs, nil
+}
+
+// saveUndoHistory writes the current undo buffer to the history file for
+// FP.FlagConfigFile.
+func saveUndoHistory() error {
+	if FP.Config.DisableUndoHistory || len(FP.UndoBuffer) == 0 {
+		return nil
+	}
+
+	file, abs, err := getUndoHistoryFile(FP.FlagConfigFile)
+	if err != nil {
+		return err
+	}
+
+	h := UndoHistory{
+		Version:    UndoHistoryVersion,
+		ConfigFile: abs,
+		SavedAt:    time.Now(),
+		Compressed: !FP.Config.DisableGzipCompressionInUndoBuffer,
+		Pos:        FP.UndoBufferPos,
+		Snapshots:  FP.UndoBuffer,
+		Times:      FP.UndoBufferTimes,
+		Checksums:  make([]string, len(FP.UndoBuffer)),
+	}
+
+	for i := range h.Snapshots {
+		h.Checksums[i] = getChecksum(h.Snapshots[i])
+	}
+
+	b, err := json.Marshal(h)
+	if err != nil {
+		return fmt.Errorf("failed to marshal undo history: %w", err)
+	}
+
+	return writeFileAtomic(file, b, 0o600)
+}
+
+// migrateUndoHistory updates a freshly loaded history that was saved by an
+// older version of this program. Histories from before the snapshots' times
+// were recorded get zero times, which are shown as unknown.
+func migrateUndoHistory(h *UndoHistory) {
+	if len(h.Times) == 0 {
+		h.Times = make([]time.Time, len(h.Snapshots))
+	}
+}
+
+// validateUndoHistory runs integrity checks against a freshly loaded history.
+func validateUndoHistory(h *UndoHistory, abs string) error {
+	if h.ConfigFile != abs {
+		return fmt.Errorf("%w: belongs to %v", ErrUndoHistoryCorrupt, h.ConfigFile)
+	}
+
+	if len(h.Snapshots) == 0 || len(h.Snapshots) != len(h.Checksums) {
+		return fmt.Errorf("%w: %v snapshots, %v checksums", ErrUndoHistoryCorrupt, len(h.Snapshots), len(h.Checksums))
+	}
+
+	if len(h.Times) != len(h.Snapshots) {
+		return fmt.Errorf("%w: %v snapshots, %v times", ErrUndoHistoryCorrupt, len(h.Snapshots), len(h.Times))
+	}
+
+	if h.Pos < 0 || h.Pos >= len(h.Snapshots) {
+		return fmt.Errorf("%w: position %v out of range", ErrUndoHistoryCorrupt, h.Pos)
+	}
+
+	for i := range h.Snapshots {
+		if getChecksum(h.Snapshots[i]) != h.Checksums[i] {
+			return fmt.Errorf("%w: checksum mismatch for snapshot %v", ErrUndoHistoryCorrupt, i)
+		}
+	}
+
+	return nil
+}
+
+// loadUndoHistory loads and validates the history file for the provided
+// config file. Returns nil and no error if there is no usable history. If the
+// file fails its integrity checks, it is renamed with a .corrupt suffix so
+// that it does not get in the way again, and an error is returned.
+func loadUndoHistory(configFile string) (*UndoHistory, error) {
+	file, abs, err := getUndoHistoryFile(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read undo history %v: %w", file, err)
+	}
+
+	h := UndoHistory{}
+
+	err = json.Unmarshal(b, &h)
+	if err == nil && h.Version != UndoHistoryVersion {
+		return nil, nil
+	}
+
+	if err == nil {
+		migrateUndoHistory(&h)
+		err = validateUndoHistory(&h, abs)
+	} else {
+		err = fmt.Errorf("%w: %w", ErrUndoHistoryCorrupt, err)
+	}
+
+	if err != nil {
+		renameErr := os.Rename(file, fmt.Sprintf("%v.corrupt", file))
+		if renameErr != nil {
+			return nil, fmt.Errorf("%w; also failed to move it aside: %w", err, renameErr)
+		}
+
+		return nil, err
+	}
+
+	return &h, nil
+}
+
+// deleteUndoHistory removes the history file for the provided config file, if
+// it exists.
+func deleteUndoHistory(configFile string) error {
+	file, _, err := getUndoHistoryFile(configFile)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(file)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete undo history %v: %w", file, err)
+	}
+
+	return nil
+}
+
+// convertSnapshot converts a snapshot so that its compression matches the
+// current undo buffer configuration.
+func convertSnapshot(b []byte, compressed bool) ([]byte, error) {
+	wantCompressed := !FP.Config.DisableGzipCompressionInUndoBuffer
+
+	switch {
+	case compressed == wantCompressed:
+		return b, nil
+	case compressed:
+		return decompress(b)
+	default:
+		return compress(b)
+	}
+}
+
+// restoreUndoHistory replaces the undo buffer with the snapshots from a
+// previous session. If the currently loaded config differs from the snapshot
+// that was active when the history was saved (for example, because the
+// previous session exited without saving, or the file was edited elsewhere),
+// any snapshots after that position are dropped and the current config is
+// appended as the latest snapshot, so that nothing currently loaded is lost.
+func restoreUndoHistory(h *UndoHistory) error {
+	current, err := yaml.Marshal(FP.Config)
+	if err != nil {
+		return fmt.Errorf("%v: %w", FP.T["UndoBufferCannotMarshalConfigError"], err)
+	}
+
+	buf := make([][]byte, len(h.Snapshots))
+	times := slices.Clone(h.Times)
+
+	for i := range h.Snapshots {
+		buf[i], err = convertSnapshot(h.Snapshots[i], h.Compressed)
+		if err != nil {
+			return fmt.Errorf("failed to convert snapshot %v: %w", i, err)
+		}
+	}
+
+	prev := h.Snapshots[h.Pos]
+	if h.Compressed {
+		prev, err = decompress(prev)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrUndoHistoryCorrupt, err)
+		}
+	}
+
+	// make sure the snapshot can actually be loaded before committing to it
+	err = yaml.Unmarshal(prev, &Config{})
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrUndoHistoryCorrupt, err)
+	}
+
+	pos := h.Pos
+
+	if !bytes.Equal(prev, current) {
+		latest := current
+		if !FP.Config.DisableGzipCompressionInUndoBuffer {
+			latest, err = compress(current)
+			if err != nil {
+				return fmt.Errorf("%v: %w", FP.T["UndoBufferConfigCompressionError"], err)
+			}
+		}
+
+		buf = append(buf[:pos+1], latest)
+		times = append(times[:pos+1], time.Now())
+		pos++
+	}
+
+	FP.UndoBuffer = buf
+	FP.UndoBufferTimes = times
+	FP.UndoBufferPos = pos
+
+	trimUndoBuffer(FP.Config.UndoBufferMaxLength, FP.Config.UndoBufferMaxBytes)
+
+	return nil
+}
+
+// promptRestoreUndoHistory checks for undo history from a previous session
+// and, if any is found, asks the user whether it should be restored. Problems
+// with the history file are only ever reported in the status text, so they
+// can never prevent the config from loading.
+func promptRestoreUndoHistory() {
+	if FP.Config.DisableUndoHistory || FP.FlagKeyboardEchoMode {
+		return
+	}
+
+	h, err := loadUndoHistory(FP.FlagConfigFile)
+	if err != nil {
+		FP.ProfileStatusText.SetText(fmt.Sprintf("%v%v: %v%v",
+			FP.Colors["ProfileStatusTextError"],
+			FP.T["UndoHistoryLoadFailed"],
+			err.Error(),
+			Reset,
+		))
+
+		return
+	}
+
+	if h == nil || len(h.Snapshots) <= 1 {
+		return
+	}
+
+	FP.PromptBox.ClearButtons().AddButtons(
+		[]string{
+			FP.T["PromptRestoreUndoHistoryButtonRestore"],
+			FP.T["PromptRestoreUndoHistoryButtonDiscard"],
+		},
+	).SetText(fmt.Sprintf("%v\n\n%v: %v\n%v: %v",
+		FP.T["PromptRestoreUndoHistoryText"],
+		FP.T["PromptRestoreUndoHistorySnapshots"],
+		len(h.Snapshots),
+		FP.T["PromptRestoreUndoHistorySavedAt"],
+		h.SavedAt.Format(time.DateTime),
+	)).SetDoneFunc(
+		func(buttonIndex int, _ /* buttonLabel */ string) {
+			switch buttonIndex {
+			case 0:
+				err := restoreUndoHistory(h)
+				if err != nil {
+					FP.ProfileStatusText.SetText(fmt.Sprintf("%v%v: %v%v",
+						FP.Colors["ProfileStatusTextError"],
+						FP.T["UndoHistoryRestoreFailed"],
+						err.Error(),
+						Reset,
+					))
+				} else {
+					FP.ProfileStatusText.SetText(fmt.Sprintf("%v%v %v%v",
+						FP.Colors["ProfileStatusTextPassive"],
+						FP.T["UndoHistoryRestored"],
+						getUndoBufferUsage(0),
+						Reset,
+					))
+				}
+			default:
+				err := deleteUndoHistory(FP.FlagConfigFile)
+				if err != nil {
+					FP.ProfileStatusText.SetText(fmt.Sprintf("%v%v%v",
+						FP.Colors["ProfileStatusTextError"],
+						err.Error(),
+						Reset,
+					))
+				}
+			}
+
+			FP.Pages.SwitchToPage(PageProfiles)
+			FP.App.SetFocus(FP.ProfileList)
+		},
+	).SetBackgroundColor(tcell.ColorDimGray).
+		SetTextColor(tcell.ColorWhite)
+
+	FP.Pages.SwitchToPage(PagePrompt)
+	FP.PromptBox.SetFocus(0)
+	FP.App.SetFocus(FP.PromptBox)
+}
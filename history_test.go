@@ -0,0 +1,79 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestValidateUndoHistory(t *testing.T) {
+	const abs = "/home/user/.config/finance-planner/config.yml"
+
+	snapshots := [][]byte{[]byte("a"), []byte("b")}
+	checksums := []string{getChecksum(snapshots[0]), getChecksum(snapshots[1])}
+	times := []time.Time{time.Unix(1, 0), time.Unix(2, 0)}
+
+	tests := []struct {
+		name    string
+		h       UndoHistory
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			h:    UndoHistory{ConfigFile: abs, Pos: 1, Snapshots: snapshots, Times: times, Checksums: checksums},
+		},
+		{
+			name: "saved before times were recorded",
+			h:    UndoHistory{ConfigFile: abs, Pos: 1, Snapshots: snapshots, Checksums: checksums},
+		},
+		{
+			name:    "another config file",
+			h:       UndoHistory{ConfigFile: "/tmp/other.yml", Pos: 1, Snapshots: snapshots, Times: times, Checksums: checksums},
+			wantErr: true,
+		},
+		{
+			name:    "missing times",
+			h:       UndoHistory{ConfigFile: abs, Pos: 1, Snapshots: snapshots, Times: times[:1], Checksums: checksums},
+			wantErr: true,
+		},
+		{
+			name:    "missing checksums",
+			h:       UndoHistory{ConfigFile: abs, Pos: 1, Snapshots: snapshots, Times: times, Checksums: checksums[:1]},
+			wantErr: true,
+		},
+		{
+			name:    "checksum mismatch",
+			h:       UndoHistory{ConfigFile: abs, Pos: 1, Snapshots: snapshots, Times: times, Checksums: []string{checksums[1], checksums[0]}},
+			wantErr: true,
+		},
+		{
+			name:    "position out of range",
+			h:       UndoHistory{ConfigFile: abs, Pos: 2, Snapshots: snapshots, Times: times, Checksums: checksums},
+			wantErr: true,
+		},
+		{
+			name:    "no snapshots",
+			h:       UndoHistory{ConfigFile: abs},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrateUndoHistory(&tt.h)
+
+			err := validateUndoHistory(&tt.h, abs)
+			if tt.wantErr {
+				if !errors.Is(err, ErrUndoHistoryCorrupt) {
+					t.Errorf("got %v, want %v", err, ErrUndoHistoryCorrupt)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Errorf("got %v, want no error", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// This file contains the holiday calendar, which determines which days are
+// business days, and the roll conventions that move transactions off of
+// weekends and holidays.
+
+// The layouts accepted for dates in the Config.Holidays list. Dates without
+// a year are holidays every year.
+const (
+	HolidayDateFormat       = time.DateOnly
+	HolidayAnnualDateFormat = "01-02"
+)
+
+// Roll conventions for transactions whose occurrences land on a weekend or a
+// holiday. An empty roll is the same as RollNone.
+const (
+	RollNone     = "NONE"
+	RollPrevious = "PREVIOUS" // move to the previous business day
+	RollNext     = "NEXT"     // move to the next business day
+)
+
+// How many days beyond the results' start and end dates are checked for
+// occurrences that may be rolled into the results.
+const RollWindow = 14
+
+// HolidayCalendar is the parsed form of Config.Holidays.
+type HolidayCalendar struct {
+	// holidays on a specific date, at UTC midnight
+	Dates map[time.Time]bool
+	// holidays that occur every year, keyed by HolidayAnnualDateFormat
+	Annual map[string]bool
+}
+
+// IsHoliday returns true if d is a holiday. d must be at UTC midnight.
+func (h HolidayCalendar) IsHoliday(d time.Time) bool {
+	return h.Dates[d] || h.Annual[d.Format(HolidayAnnualDateFormat)]
+}
+
+// parseHoliday adds a single entry from Config.Holidays to the calendar.
+func (h HolidayCalendar) parseHoliday(holiday string) error {
+	holiday = strings.TrimSpace(holiday)
+
+	d, err := time.Parse(HolidayDateFormat, holiday)
+	if err == nil {
+		h.Dates[d] = true
+
+		return nil
+	}
+
+	a, aerr := time.Parse(HolidayAnnualDateFormat, holiday)
+	if aerr == nil {
+		h.Annual[a.Format(HolidayAnnualDateFormat)] = true
+
+		return nil
+	}
+
+	return fmt.Errorf("%v %v: %w", FP.T["HolidayInvalid"], holiday, err)
+}
+
+// getHolidays parses the configured holidays into a calendar. If any of them
+// aren't a valid YYYY-MM-DD or MM-DD date, the first such error is returned
+// alongside the calendar of all valid holidays.
+func getHolidays(conf *Config) (HolidayCalendar, error) {
+	h := HolidayCalendar{
+		Dates:  make(map[time.Time]bool, len(conf.Holidays)),
+		Annual: make(map[string]bool),
+	}
+
+	var first error
+
+	for _, holiday := range conf.Holidays {
+		err := h.parseHoliday(holiday)
+		if err != nil && first == nil {
+			first = err
+		}
+	}
+
+	return h, first
+}
+
+// isBusinessDay returns true if d is a weekday that isn't a holiday. d must be
+// at UTC midnight.
+func isBusinessDay(d time.Time, holidays HolidayCalendar) bool {
+	wd := d.Weekday()
+
+	return wd != time.Saturday && wd != time.Sunday && !holidays.IsHoliday(d)
+}
+
+// getRoll returns the roll convention, treating an empty one as RollNone.
+func getRoll(roll string) string {
+	if roll == "" {
+		return RollNone
+	}
+
+	return roll
+}
+
+// rollDate moves d to the nearest business day in the direction given by the
+// roll convention. d is returned as-is if it is already a business day, or if
+// the roll convention is RollNone.
+func rollDate(d time.Time, roll string, holidays HolidayCalendar) time.Time {
+	step := 0
+
+	switch roll {
+	case RollPrevious:
+		step = -1
+	case RollNext:
+		step = 1
+	default:
+		return d
+	}
+
+	for !isBusinessDay(d, holidays) {
+		d = d.AddDate(0, 0, step)
+	}
+
+	return d
+}
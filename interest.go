@@ -0,0 +1,221 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	lib "github.com/charles-m-knox/finance-planner-lib"
+)
+
+// This file contains the logic for interest on the running balance of the
+// results. A profile can earn interest on a positive balance (its APY) and be
+// charged interest on a negative balance (its APR). Interest accrues every day
+// and is added to the balance as its own line in each result, but it only
+// earns interest itself once it has been compounded at the end of each
+// compounding period.
+
+// The number of days per year that daily interest rates are based on.
+const DaysPerYear = 365
+
+// InterestTotals is the total interest over a set of results.
+type InterestTotals struct {
+	// the interest earned on a positive balance, in cents
+	Earned int
+	// the interest charged on a negative balance, in cents (always <= 0)
+	Charged int
+}
+
+// hasInterest returns true if the profile earns or is charged interest.
+func hasInterest(p *Profile) bool {
+	return p.APY != 0 || p.APR != 0
+}
+
+// getCompounding returns the profile's compounding period, which is one of
+// DAILY, MONTHLY or YEARLY. It defaults to MONTHLY.
+func getCompounding(compounding string) string {
+	switch strings.ToUpper(strings.TrimSpace(compounding)) {
+	case DAILY:
+		return DAILY
+	case YEARLY:
+		return YEARLY
+	default:
+		return MONTHLY
+	}
+}
+
+// getCompoundingPeriodsPerYear returns the number of times that interest is
+// compounded each year.
+func getCompoundingPeriodsPerYear(compounding string) float64 {
+	switch getCompounding(compounding) {
+	case DAILY:
+		return DaysPerYear
+	case YEARLY:
+		return 1
+	default:
+		return 12
+	}
+}
+
+// getCompoundingPeriod returns a value that identifies the compounding period
+// that d falls in, so that the start of a new period can be detected.
+func getCompoundingPeriod(compounding string, d time.Time) int {
+	switch getCompounding(compounding) {
+	case DAILY:
+		return int(d.Unix() / (60 * 60 * 24))
+	case YEARLY:
+		return d.Year()
+	default:
+		return d.Year()*12 + int(d.Month())
+	}
+}
+
+// getDailyInterestRates returns the rates at which interest accrues each day
+// on a positive and a negative balance, respectively. The APY is an effective
+// yearly rate, so it is converted to the rate per compounding period first,
+// whereas the APR is a nominal rate that is simply divided between periods.
+func getDailyInterestRates(p *Profile) (float64, float64) {
+	n := getCompoundingPeriodsPerYear(p.InterestCompounding)
+	apy := (math.Pow(1+p.APY/100, 1/n) - 1) * n / DaysPerYear
+	apr := p.APR / 100 / DaysPerYear
+
+	return apy, apr
+}
+
+// getInterestLine returns the line that is shown in the day's transactions
+// for the interest that was credited or charged on that day.
+func getInterestLine(label string, amount int) string {
+	return fmt.Sprintf("%v (%v)", label, lib.FormatAsCurrency(amount))
+}
+
+// getDailyInterest returns the interest that accrues on each day of the
+// results, starting from the starting balance. Each day's interest is based on
+// the balance at the end of the previous day, including any interest that has
+// been compounded by then. Fractions of a cent are carried over to the next
+// day, so that they are not lost to rounding.
+func getDailyInterest(results []lib.Result, startBalance int, p *Profile) []int {
+	interest := make([]int, len(results))
+
+	if !hasInterest(p) || len(results) == 0 {
+		return interest
+	}
+
+	apy, apr := getDailyInterestRates(p)
+
+	balance := startBalance
+	// the total amount of interest so far
+	added := 0
+	// the exact amount of interest accrued so far, of which added is the
+	// rounded amount
+	accrued := 0.0
+	// the interest added in the current compounding period, which does not
+	// earn interest until the period is over
+	uncompounded := 0
+	period := getCompoundingPeriod(p.InterestCompounding, results[0].Date)
+
+	for i := range results {
+		if pd := getCompoundingPeriod(p.InterestCompounding, results[i].Date); pd != period {
+			period = pd
+			uncompounded = 0
+		}
+
+		principal := float64(balance - uncompounded)
+		if principal >= 0 {
+			accrued += principal * apy
+		} else {
+			accrued += principal * apr
+		}
+
+		interest[i] = int(math.Round(accrued)) - added
+		added += interest[i]
+		uncompounded += interest[i]
+
+		balance = results[i].Balance + added
+	}
+
+	return interest
+}
+
+// addInterest adds the daily interest to the results, as its own line in each
+// day's transactions. Interest is treated the same as income (or an expense,
+// if it is charged) on that day.
+func addInterest(results []lib.Result, interest []int, label string) InterestTotals {
+	totals := InterestTotals{}
+	added := 0
+
+	for i := range results {
+		r := &results[i]
+
+		if interest[i] != 0 {
+			line := getInterestLine(label, interest[i])
+
+			r.DayTransactionNamesSlice = append(r.DayTransactionNamesSlice, line)
+			if r.DayTransactionNames == "" {
+				r.DayTransactionNames = line
+			} else {
+				r.DayTransactionNames += fmt.Sprintf("; %v", line)
+			}
+
+			if interest[i] > 0 {
+				r.DayIncome += interest[i]
+				totals.Earned += interest[i]
+			} else {
+				r.DayExpenses += interest[i]
+				totals.Charged += interest[i]
+			}
+
+			r.DayNet += interest[i]
+			added += interest[i]
+		}
+
+		r.Balance += added
+		r.CumulativeIncome += totals.Earned
+		r.CumulativeExpenses += totals.Charged
+		r.DiffFromStart += added
+	}
+
+	return totals
+}
+
+// applyInterest adds the interest that accrues every day on the running
+// balance to the results, starting from the starting balance.
+func applyInterest(results []lib.Result, startBalance int, p *Profile) InterestTotals {
+	return addInterest(results, getDailyInterest(results, startBalance, p), FP.T["ResultsInterestLine"])
+}
+
+// getInterestStats returns the interest totals as text, to be shown after
+// the other statistics about the results.
+func getInterestStats(totals InterestTotals) string {
+	return fmt.Sprintf("%v: %v\n%v: %v\n%v: %v",
+		FP.T["ResultsStatsInterestEarned"],
+		lib.FormatAsCurrency(totals.Earned),
+		FP.T["ResultsStatsInterestCharged"],
+		lib.FormatAsCurrency(totals.Charged),
+		FP.T["ResultsStatsInterestNet"],
+		lib.FormatAsCurrency(totals.Earned+totals.Charged),
+	)
+}
+
+// parseInterestRate parses an interest rate given as a percentage, such as
+// "4.5" or "4.5%". Empty text is a rate of 0.
+func parseInterestRate(s string) (float64, error) {
+	s = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(s), "%"))
+	if s == "" {
+		return 0, nil
+	}
+
+	rate, err := strconv.ParseFloat(s, 64)
+	if err != nil || rate < 0 {
+		return 0, fmt.Errorf("%v: %v", FP.T["InterestRateInvalid"], s)
+	}
+
+	return rate, nil
+}
+
+// formatInterestRate formats an interest rate so that it can be parsed again
+// with parseInterestRate.
+func formatInterestRate(rate float64) string {
+	return strconv.FormatFloat(rate, 'f', -1, 64)
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	lib "github.com/charles-m-knox/finance-planner-lib"
+)
+
+// getTestResults returns the given number of days of results, starting at
+// start, on which the balance before interest stays at balance.
+func getTestResults(start time.Time, days, balance int) []lib.Result {
+	results := make([]lib.Result, days)
+	for i := range results {
+		results[i] = lib.Result{Date: start.AddDate(0, 0, i), Balance: balance}
+	}
+
+	return results
+}
+
+func TestGetDailyInterest(t *testing.T) {
+	tests := []struct {
+		name    string
+		start   time.Time
+		balance int
+		profile Profile
+		// the total interest over a year of results
+		want int
+	}{
+		{
+			name:    "5% APY compounded daily",
+			start:   time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+			balance: 1000000,
+			profile: Profile{APY: 5, InterestCompounding: DAILY},
+			want:    50000,
+		},
+		{
+			name:    "5% APY compounded monthly",
+			start:   time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+			balance: 1000000,
+			profile: Profile{APY: 5, InterestCompounding: MONTHLY},
+			want:    50000,
+		},
+		{
+			name:    "5% APY compounded yearly",
+			start:   time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+			balance: 1000000,
+			profile: Profile{APY: 5, InterestCompounding: YEARLY},
+			want:    50000,
+		},
+		{
+			name:    "5% APY compounded monthly, starting mid-year",
+			start:   time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC),
+			balance: 1000000,
+			profile: Profile{APY: 5, InterestCompounding: MONTHLY},
+			want:    50000,
+		},
+		{
+			name:    "APY on a negative balance",
+			start:   time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+			balance: -1000000,
+			profile: Profile{APY: 5},
+			want:    0,
+		},
+		{
+			name:    "APR on a positive balance",
+			start:   time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+			balance: 1000000,
+			profile: Profile{APR: 20},
+			want:    0,
+		},
+		{
+			name:    "no interest",
+			start:   time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+			balance: 1000000,
+			profile: Profile{},
+			want:    0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := getTestResults(tt.start, DaysPerYear, tt.balance)
+
+			total := 0
+			for _, interest := range getDailyInterest(results, tt.balance, &tt.profile) {
+				total += interest
+			}
+
+			if total != tt.want {
+				t.Errorf("got %v interest, want %v", total, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	lib "github.com/charles-m-knox/finance-planner-lib"
+)
+
+// This file contains the logic for investment accounts, such as a brokerage
+// or retirement account. Their balance grows at an expected annual return
+// instead of earning the profile's interest, and it is not cash, so it is not
+// part of the results' balance. Contributions are transfers from a cash
+// account into the investment account, which lower the balance, while the
+// net worth includes the investments.
+
+const (
+	// The type of account that investment accounts are.
+	AccountTypeInvestment = "INVESTMENT"
+
+	// The prefix of an investment account's expected annual return when
+	// accounts are edited as text, such as "brokerage $10,000.00 invest:7%".
+	InvestmentPrefix = "invest:"
+)
+
+// isInvestment returns true if the account is an investment account.
+func isInvestment(a *Account) bool {
+	return strings.EqualFold(a.Type, AccountTypeInvestment)
+}
+
+// getInvestment returns the profile's investment account with the given name,
+// or nil if the account is not an investment account.
+func getInvestment(p *Profile, account string) *Account {
+	for i := range p.Accounts {
+		if p.Accounts[i].Name == account && isInvestment(&p.Accounts[i]) {
+			return &p.Accounts[i]
+		}
+	}
+
+	return nil
+}
+
+// isCash returns true if the account's balance is part of the results'
+// balance, which is true for every account except investment accounts.
+func isCash(p *Profile, account string) bool {
+	return getInvestment(p, account) == nil
+}
+
+// hasInvestments returns true if any of the profile's accounts is an
+// investment account.
+func hasInvestments(p *Profile) bool {
+	for i := range p.Accounts {
+		if isInvestment(&p.Accounts[i]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getInvestmentGrowth returns the growth of the investment account's balance
+// on each day of its results, starting from the starting balance. The growth
+// compounds every day, so that it adds up to the expected annual return over
+// a year. This is the same as the interest on a daily compounded APY.
+func getInvestmentGrowth(results []lib.Result, startBalance int, a *Account) []int {
+	return getDailyInterest(results, startBalance, &Profile{APY: a.Return, InterestCompounding: DAILY})
+}
+
+// getNetWorth returns the net worth at the end of each day of the results,
+// which is the balance, plus the balance of each investment account, minus
+// the remaining principal of each loan. It is nil if the profile has neither
+// investments nor loans, since the net worth would be the same as the
+// balance.
+func getNetWorth(p *Profile, results []lib.Result, extra ResultsExtra) []int {
+	if !hasInvestments(p) && len(extra.Loans.Names) == 0 {
+		return nil
+	}
+
+	netWorth := make([]int, len(results))
+
+	for i := range results {
+		netWorth[i] = results[i].Balance
+
+		for j, balance := range extra.Accounts.GetBalances(i) {
+			if !isCash(p, extra.Accounts.Names[j]) {
+				netWorth[i] += balance
+			}
+		}
+
+		for _, balance := range extra.Loans.GetBalances(i) {
+			netWorth[i] += balance
+		}
+	}
+
+	return netWorth
+}
+
+// formatInvestment formats an investment account's expected annual return as
+// text, such as "invest:7%". It can be parsed with parseInvestment.
+func formatInvestment(a *Account) string {
+	return fmt.Sprintf("%v%v%%", InvestmentPrefix, formatInterestRate(a.Return))
+}
+
+// parseInvestment parses an investment account's expected annual return that
+// was formatted with formatInvestment into the account.
+func parseInvestment(s string, a *Account) error {
+	rate, err := parseInterestRate(strings.TrimPrefix(strings.TrimSpace(s), InvestmentPrefix))
+	if err != nil {
+		return fmt.Errorf("%v: %w", FP.T["InvestmentInvalidReturn"], err)
+	}
+
+	a.Type = AccountTypeInvestment
+	a.Return = rate
+
+	return nil
+}
@@ -0,0 +1,432 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	lib "github.com/charles-m-knox/finance-planner-lib"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// This file contains the logic for loans, such as mortgages. A loan is a
+// transaction whose amount is the loan's monthly payment, which is split
+// between the interest on the remaining principal and paying the principal
+// down, so that the loan is paid off by the end of its term. The remaining
+// principal is shown as a liability in the results.
+
+// The number of loan payments per year, which the loan's rate is divided by.
+const LoanPaymentsPerYear = 12
+
+// Loan is the definition of an amortized loan that a transaction makes the
+// payments of. Each occurrence of the transaction is one payment.
+type Loan struct {
+	// the amount borrowed, in cents
+	Principal int `yaml:"principal"`
+	// the annual percentage rate, as a percentage
+	Rate float64 `yaml:"rate"`
+	// the number of monthly payments that the loan is paid off in
+	Term int `yaml:"term"`
+	// an extra amount, in cents, that is paid towards the principal with
+	// every payment, which pays the loan off early
+	ExtraPrincipal int `yaml:"extraPrincipal,omitempty"`
+}
+
+// AmortizationPayment is a single payment in a loan's amortization schedule.
+// All amounts are positive and in cents.
+type AmortizationPayment struct {
+	Date time.Time
+	// the regular payment, which is Interest + Principal
+	Payment   int
+	Interest  int
+	Principal int
+	// the extra payment towards the principal
+	Extra int
+	// the principal that remains after this payment
+	Remaining int
+}
+
+// isLoan returns true if the transaction makes the payments of a loan.
+func isLoan(tx *TX) bool {
+	return tx.Loan.Principal > 0 && tx.Loan.Term > 0
+}
+
+// getLoanMonthlyRate returns the rate at which interest is charged each month
+// on the loan's remaining principal.
+func getLoanMonthlyRate(loan Loan) float64 {
+	return loan.Rate / 100 / LoanPaymentsPerYear
+}
+
+// getLoanPayment returns the regular monthly payment, in cents, that pays the
+// loan off over its term, excluding any extra principal.
+func getLoanPayment(loan Loan) int {
+	if loan.Term < 1 {
+		return 0
+	}
+
+	r := getLoanMonthlyRate(loan)
+	p := float64(loan.Principal)
+
+	if r == 0 {
+		return int(math.Ceil(p / float64(loan.Term)))
+	}
+
+	return int(math.Round(p * r / (1 - math.Pow(1+r, -float64(loan.Term)))))
+}
+
+// getLoanPaymentDates returns the dates of the loan's payments, which are the
+// first occurrences of the transaction, up to the loan's term. The
+// transaction's end date and occurrences limit are ignored, since they are
+// derived from the schedule. Like any transaction with a limited number of
+// occurrences, it needs a start date.
+func getLoanPaymentDates(tx *TX, holidays HolidayCalendar) ([]time.Time, error) {
+	t := *tx
+	t.Occurrences = t.Loan.Term
+	clearTXEnds(&t)
+
+	dates, err := getTXOccurrences(&t, time.Time{}, time.Time{}, MaxFinalOccurrenceDate, holidays)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(dates) > t.Loan.Term {
+		dates = dates[:t.Loan.Term]
+	}
+
+	return dates, nil
+}
+
+// getAmortizationSchedule returns the loan's payments on the given dates,
+// until the loan is paid off. Each payment first pays the interest on the
+// remaining principal, and the rest of it, plus any extra principal, pays the
+// principal down. The final payment pays whatever principal remains, which
+// includes any that was left over from rounding the payment to cents.
+func getAmortizationSchedule(loan Loan, dates []time.Time) []AmortizationPayment {
+	schedule := []AmortizationPayment{}
+	payment := getLoanPayment(loan)
+	r := getLoanMonthlyRate(loan)
+	remaining := loan.Principal
+
+	for i, d := range dates {
+		if remaining <= 0 {
+			break
+		}
+
+		interest := int(math.Round(float64(remaining) * r))
+		principal := min(max(payment-interest, 0), remaining)
+		extra := min(max(loan.ExtraPrincipal, 0), remaining-principal)
+
+		if i == len(dates)-1 {
+			principal = remaining - extra
+		}
+
+		remaining -= principal + extra
+
+		schedule = append(schedule, AmortizationPayment{
+			Date:      d,
+			Payment:   interest + principal,
+			Interest:  interest,
+			Principal: principal,
+			Extra:     extra,
+			Remaining: remaining,
+		})
+	}
+
+	return schedule
+}
+
+// getLoanSchedule returns the amortization schedule of the transaction's loan.
+func getLoanSchedule(tx *TX, holidays HolidayCalendar) ([]AmortizationPayment, error) {
+	dates, err := getLoanPaymentDates(tx, holidays)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", tx.Name, err)
+	}
+
+	return getAmortizationSchedule(tx.Loan, dates), nil
+}
+
+// getAmortizationTotals returns the total interest and the total of all
+// payments in the schedule.
+func getAmortizationTotals(schedule []AmortizationPayment) (int, int) {
+	interest, paid := 0, 0
+
+	for i := range schedule {
+		interest += schedule[i].Interest
+		paid += schedule[i].Payment + schedule[i].Extra
+	}
+
+	return interest, paid
+}
+
+// applyLoan updates the transaction so that its occurrences are the payments
+// of its loan: its amount is the regular payment plus any extra principal,
+// and it ends with the final payment, which is changed to whatever is left
+// to pay, if that is different.
+func applyLoan(tx *TX, holidays HolidayCalendar) error {
+	schedule, err := getLoanSchedule(tx, holidays)
+	if err != nil {
+		return err
+	}
+
+	tx.Amount = -(getLoanPayment(tx.Loan) + max(tx.Loan.ExtraPrincipal, 0))
+	tx.AmountChanges = nil
+	tx.Escalation = 0
+	tx.Occurrences = len(schedule)
+	clearTXEnds(tx)
+
+	if len(schedule) == 0 {
+		return nil
+	}
+
+	last := schedule[len(schedule)-1]
+	tx.EndsDay = last.Date.Day()
+	tx.EndsMonth = int(last.Date.Month())
+	tx.EndsYear = last.Date.Year()
+
+	if final := -(last.Payment + last.Extra); final != tx.Amount {
+		tx.AmountChanges = []AmountChange{{Date: last.Date.Format(time.DateOnly), Amount: final}}
+	}
+
+	return nil
+}
+
+// updateLoans re-applies each of the profile's loans to its transaction, so
+// that the payments stay in sync with the loan after the transaction is
+// edited, such as when its start date, frequency or amount is changed. Loans
+// whose payments can't be derived, such as ones without a start date, are
+// left as they are; the results report their error.
+func updateLoans(p *Profile, holidays HolidayCalendar) {
+	for i := range p.TX {
+		if !isLoan(&p.TX[i]) {
+			continue
+		}
+
+		tx := p.TX[i]
+		if applyLoan(&tx, holidays) == nil {
+			p.TX[i] = tx
+		}
+	}
+}
+
+// getRemainingPrincipal returns the principal that remains after the last
+// payment in the schedule on or before d.
+func getRemainingPrincipal(loan Loan, schedule []AmortizationPayment, d time.Time) int {
+	remaining := loan.Principal
+
+	for i := range schedule {
+		if schedule[i].Date.After(d) {
+			break
+		}
+
+		remaining = schedule[i].Remaining
+	}
+
+	return remaining
+}
+
+// getLoanBalances returns the remaining principal of each of the profile's
+// active loans on every day of the results, as a negative balance, since it
+// is owed.
+func getLoanBalances(p *Profile, results []lib.Result, holidays HolidayCalendar) (AccountBalances, error) {
+	loans := AccountBalances{}
+	schedules := [][]AmortizationPayment{}
+	txs := []*TX{}
+
+	for i := range p.TX {
+		tx := &p.TX[i]
+		if !tx.Active || !isLoan(tx) {
+			continue
+		}
+
+		schedule, err := getLoanSchedule(tx, holidays)
+		if err != nil {
+			return loans, err
+		}
+
+		loans.Names = append(loans.Names, tx.Name)
+		schedules = append(schedules, schedule)
+		txs = append(txs, tx)
+	}
+
+	if len(txs) == 0 {
+		return loans, nil
+	}
+
+	loans.Balances = make([][]int, len(results))
+
+	for i := range results {
+		loans.Balances[i] = make([]int, len(txs))
+
+		for j := range txs {
+			loans.Balances[i][j] = -getRemainingPrincipal(txs[j].Loan, schedules[j], results[i].Date)
+		}
+	}
+
+	return loans, nil
+}
+
+// getLoanColumnName returns the name of a loan's column in the results, such
+// as "mortgage (loan)".
+func getLoanColumnName(name string) string {
+	return fmt.Sprintf("%v (%v)", name, FP.T["ResultsColumnLoan"])
+}
+
+// parseLoanTerm parses a loan's term, given as a number of months, or as a
+// number of years when followed by "y", such as "30y".
+func parseLoanTerm(s string) (int, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	multiplier := 1
+
+	if strings.HasSuffix(s, "y") {
+		s = strings.TrimSpace(strings.TrimSuffix(s, "y"))
+		multiplier = LoanPaymentsPerYear
+	}
+
+	term, err := strconv.Atoi(s)
+	if err != nil || term < 1 {
+		return 0, fmt.Errorf("%v: %v", FP.T["LoanInvalidTerm"], s)
+	}
+
+	return term * multiplier, nil
+}
+
+// loanWizardStep is one of the values that the loan wizard prompts for.
+type loanWizardStep struct {
+	// the translation key of the step's prompt
+	Label string
+	// the value that the prompt starts with
+	Value string
+	// parses the user's input into the new loan, returning an error if it is
+	// invalid
+	Set func(s string) error
+}
+
+// promptLoanWizard prompts for a loan's principal, rate, term and first
+// payment date, one after the other, in the transactions input field. Once
+// all of them have been entered, a new transaction that makes the loan's
+// monthly payments is passed to add. Nothing is added if the user cancels.
+func promptLoanWizard(add func(tx TX)) {
+	now := time.Now()
+	firstOfNextMonth := time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+
+	tx := getNewTX(now)
+	tx.Name = FP.T["LoanDefaultName"]
+	tx.Frequency = MONTHLY
+	tx.Interval = 1
+
+	steps := []loanWizardStep{
+		{
+			Label: "LoanWizardPrincipalLabel",
+			Set: func(s string) error {
+				tx.Loan.Principal = int(lib.ParseDollarAmount(s, true))
+				if tx.Loan.Principal <= 0 {
+					return fmt.Errorf("%v: %v", FP.T["LoanInvalidPrincipal"], s)
+				}
+
+				return nil
+			},
+		},
+		{
+			Label: "LoanWizardRateLabel",
+			Set: func(s string) error {
+				rate, err := parseInterestRate(s)
+				tx.Loan.Rate = rate
+
+				return err
+			},
+		},
+		{
+			Label: "LoanWizardTermLabel",
+			Set: func(s string) error {
+				term, err := parseLoanTerm(s)
+				tx.Loan.Term = term
+
+				return err
+			},
+		},
+		{
+			Label: "LoanWizardStartLabel",
+			Value: firstOfNextMonth.Format(time.DateOnly),
+			Set: func(s string) error {
+				d, err := time.Parse(time.DateOnly, strings.TrimSpace(s))
+				if err != nil {
+					return fmt.Errorf("%v: %v", FP.T["LoanInvalidStart"], s)
+				}
+
+				tx.StartsDay = d.Day()
+				tx.StartsMonth = int(d.Month())
+				tx.StartsYear = d.Year()
+
+				return nil
+			},
+		},
+	}
+
+	// the autocomplete can't be reset from within the input field's done
+	// func, which prompts for each step after the first one
+	resetTransactionsInputFieldAutocomplete()
+
+	promptLoanWizardStep(steps, 0, func() error {
+		holidays, err := getHolidays(&FP.Config)
+		if err != nil {
+			return err
+		}
+
+		err = applyLoan(&tx, holidays)
+		if err != nil {
+			return err
+		}
+
+		add(tx)
+
+		return nil
+	})
+}
+
+// promptLoanWizardStep prompts for the i'th step of the loan wizard, and then
+// for the next one, until all of them have been entered and done is called.
+// Invalid input is refused with the reason shown in the prompt.
+func promptLoanWizardStep(steps []loanWizardStep, i int, done func() error) {
+	if i >= len(steps) {
+		err := done()
+		if err != nil {
+			FP.ProfileStatusText.SetText(fmt.Sprintf("%v%v%v",
+				FP.Colors["ProfileStatusTextError"],
+				tview.Escape(err.Error()),
+				Reset,
+			))
+		}
+
+		return
+	}
+
+	step := steps[i]
+	label := fmt.Sprintf("%v (%v/%v) %v:", FP.T["LoanWizardTitle"], i+1, len(steps), FP.T[step.Label])
+
+	activateTransactionsInputFieldNoAutocompleteReset(label, step.Value)
+
+	FP.TransactionsInputField.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEscape {
+			deactivateTransactionsInputField()
+
+			return
+		}
+
+		err := step.Set(FP.TransactionsInputField.GetText())
+		if err != nil {
+			FP.TransactionsInputField.SetLabel(fmt.Sprintf("%v:", tview.Escape(err.Error())))
+
+			return // don't drop focus - the user entered invalid input
+		}
+
+		if i == len(steps)-1 {
+			deactivateTransactionsInputField()
+		}
+
+		promptLoanWizardStep(steps, i+1, done)
+	})
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	lib "github.com/charles-m-knox/finance-planner-lib"
+)
+
+// getMonthlyDates returns n dates, one month apart, starting at start.
+func getMonthlyDates(start time.Time, n int) []time.Time {
+	dates := make([]time.Time, n)
+	for i := range dates {
+		dates[i] = start.AddDate(0, i, 0)
+	}
+
+	return dates
+}
+
+func TestGetAmortizationSchedule(t *testing.T) {
+	start := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		loan Loan
+		// the number of payments that the loan should be paid off in
+		payments int
+	}{
+		{
+			name:     "30 year mortgage",
+			loan:     Loan{Principal: 20000000, Rate: 6, Term: 360},
+			payments: 360,
+		},
+		{
+			name:     "30 year mortgage with extra principal",
+			loan:     Loan{Principal: 20000000, Rate: 6, Term: 360, ExtraPrincipal: 20000},
+			payments: 252,
+		},
+		{
+			name:     "5 year car loan",
+			loan:     Loan{Principal: 3000000, Rate: 7.25, Term: 60},
+			payments: 60,
+		},
+		{
+			name:     "interest free",
+			loan:     Loan{Principal: 100000, Rate: 0, Term: 7},
+			payments: 7,
+		},
+		{
+			name:     "extra principal larger than the loan",
+			loan:     Loan{Principal: 100000, Rate: 5, Term: 12, ExtraPrincipal: 500000},
+			payments: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := getAmortizationSchedule(tt.loan, getMonthlyDates(start, tt.loan.Term))
+
+			if len(schedule) != tt.payments {
+				t.Fatalf("got %v payments, want %v", len(schedule), tt.payments)
+			}
+
+			if remaining := schedule[len(schedule)-1].Remaining; remaining != 0 {
+				t.Errorf("got %v remaining after the final payment, want 0", remaining)
+			}
+
+			paid := 0
+			for i := range schedule {
+				paid += schedule[i].Principal + schedule[i].Extra
+			}
+
+			if paid != tt.loan.Principal {
+				t.Errorf("got %v principal paid, want %v", paid, tt.loan.Principal)
+			}
+		})
+	}
+}
+
+func TestUpdateLoans(t *testing.T) {
+	// a $1,000.00 loan at 5% that is paid off in 12 monthly payments
+	tx := getNewTX(time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC))
+	tx.Loan = Loan{Principal: 100000, Rate: 5, Term: 12}
+
+	err := applyLoan(&tx, HolidayCalendar{})
+	if err != nil {
+		t.Fatalf("failed to apply the loan: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		edit func(tx *TX)
+		// the date of the final payment
+		want string
+	}{
+		{
+			name: "unchanged",
+			edit: func(_ *TX) {},
+			want: "2025-12-01",
+		},
+		{
+			name: "start date",
+			edit: func(tx *TX) { tx.StartsYear = 2026 },
+			want: "2026-12-01",
+		},
+		{
+			name: "frequency",
+			edit: func(tx *TX) { tx.Frequency = DAILY },
+			want: "2025-01-12",
+		},
+		{
+			name: "amount",
+			edit: func(tx *TX) { tx.Amount = -100 },
+			want: "2025-12-01",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Profile{TX: []TX{duplicateTX(&tx, time.Now())}}
+			tt.edit(&p.TX[0])
+
+			updateLoans(&p, HolidayCalendar{})
+
+			got := p.TX[0]
+			if got.Amount != -(getLoanPayment(tx.Loan)) || got.Occurrences != tx.Loan.Term {
+				t.Errorf("got %v payments of %v, want %v of %v", got.Occurrences, got.Amount, tx.Loan.Term, -getLoanPayment(tx.Loan))
+			}
+
+			ends := lib.GetDateString(got.EndsYear, got.EndsMonth, got.EndsDay)
+			if ends != tt.want {
+				t.Errorf("got a final payment on %v, want %v", ends, tt.want)
+			}
+
+			if len(got.AmountChanges) > 1 || (len(got.AmountChanges) == 1 && got.AmountChanges[0].Date != tt.want) {
+				t.Errorf("got amount changes %v, want at most the final payment's", got.AmountChanges)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	lib "github.com/charles-m-knox/finance-planner-lib"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// This file contains the logic for low balance alerts, which answer the
+// question of when the money runs out: the first day that the balance drops
+// below the profile's minimum balance, the first day that it is negative, and
+// the day with the lowest balance. Rows below the minimum balance are
+// highlighted in the results, and each of those days can be jumped to.
+
+// BalanceAlerts are the notable days of the results, as indexes into the
+// results, or -1 if there is no such day.
+type BalanceAlerts struct {
+	// the first day that the balance is below the minimum balance, if the
+	// profile has one
+	BelowMinimum int
+	// the first day that the balance is negative
+	Negative int
+	// the first day with the lowest balance
+	Lowest int
+}
+
+// hasMinimumBalance returns true if the profile has a minimum balance that
+// its results are checked against.
+func hasMinimumBalance(p *Profile) bool {
+	return strings.TrimSpace(p.MinimumBalance) != ""
+}
+
+// getMinimumBalance returns the profile's minimum balance, in cents.
+func getMinimumBalance(p *Profile) int {
+	return int(lib.ParseDollarAmount(p.MinimumBalance, true))
+}
+
+// getBalanceAlerts finds the notable days of the profile's results.
+func getBalanceAlerts(p *Profile, results []lib.Result) BalanceAlerts {
+	alerts := BalanceAlerts{BelowMinimum: -1, Negative: -1, Lowest: -1}
+	minimum := getMinimumBalance(p)
+
+	for i := range results {
+		balance := results[i].Balance
+
+		if alerts.BelowMinimum < 0 && hasMinimumBalance(p) && balance < minimum {
+			alerts.BelowMinimum = i
+		}
+
+		if alerts.Negative < 0 && balance < 0 {
+			alerts.Negative = i
+		}
+
+		if alerts.Lowest < 0 || balance < results[alerts.Lowest].Balance {
+			alerts.Lowest = i
+		}
+	}
+
+	return alerts
+}
+
+// getResultsRowColor returns the background color of a row with the given
+// balance in the results table: negative balances and balances below the
+// profile's minimum balance are highlighted.
+func getResultsRowColor(p *Profile, balance int) tcell.Color {
+	switch {
+	case balance < 0:
+		return tcell.GetColor(FP.Colors["ResultsRowNegativeColor"])
+	case p != nil && hasMinimumBalance(p) && balance < getMinimumBalance(p):
+		return tcell.GetColor(FP.Colors["ResultsRowBelowMinimumColor"])
+	}
+
+	return tcell.ColorReset
+}
+
+// getBalanceAlertDate returns the date of the i'th result, or a placeholder if
+// there is no such day.
+func getBalanceAlertDate(results []lib.Result, i int) string {
+	if i < 0 || i >= len(results) {
+		return FP.T["ResultsStatsNever"]
+	}
+
+	return lib.GetNowDateString(results[i].Date)
+}
+
+// getBalanceAlertsStats returns the notable days of the results as text, to
+// be shown after the other statistics about the results.
+func getBalanceAlertsStats(p *Profile, results []lib.Result, alerts BalanceAlerts) string {
+	var sb strings.Builder
+
+	if hasMinimumBalance(p) {
+		sb.WriteString(fmt.Sprintf("%v %v: %v\n",
+			FP.T["ResultsStatsFirstBelowMinimum"],
+			lib.FormatAsCurrency(getMinimumBalance(p)),
+			getBalanceAlertDate(results, alerts.BelowMinimum),
+		))
+	}
+
+	sb.WriteString(fmt.Sprintf("%v: %v\n", FP.T["ResultsStatsFirstNegative"], getBalanceAlertDate(results, alerts.Negative)))
+
+	if alerts.Lowest >= 0 {
+		sb.WriteString(fmt.Sprintf("%v: %v (%v)",
+			FP.T["ResultsStatsLowestBalance"],
+			lib.FormatAsCurrency(results[alerts.Lowest].Balance),
+			getBalanceAlertDate(results, alerts.Lowest),
+		))
+	}
+
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// jumpToBalanceAlert selects the i'th result's row in the results table, or
+// the row of its group if the table is grouped, or shows that there is no such
+// day.
+func jumpToBalanceAlert(i int, name string) {
+	if FP.LatestResults == nil || i < 0 || i >= len(*FP.LatestResults) {
+		FP.ResultsDescription.SetText(fmt.Sprintf("%v%v: %v%v",
+			FP.Colors["ResultsDescriptionPassive"],
+			name,
+			FP.T["ResultsStatsNever"],
+			Reset,
+		))
+
+		return
+	}
+
+	selectResultsDay(i)
+	FP.App.SetFocus(FP.ResultsTable)
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"embed"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
@@ -48,6 +49,14 @@ const (
 	// Its primary purpose is for use in switch/case statements to determine the
 	// current page.
 	PagePrompt = "Prompt"
+	// PageHistory is not shown to the user ever, and is only used in the code.
+	// Its primary purpose is for use in switch/case statements to determine the
+	// current page.
+	PageHistory = "History"
+	// PageAmortization is not shown to the user ever, and is only used in the
+	// code. Its primary purpose is for use in switch/case statements to
+	// determine the current page.
+	PageAmortization = "Amortization"
 )
 
 type FinancePlanner struct {
@@ -125,6 +134,10 @@ type FinancePlanner struct {
 	TransactionsTable      *tview.Table
 	TransactionsInputField *tview.InputField
 
+	// Lists the savings goals of the selected profile, below the
+	// TransactionsTable. Goals are edited with the TransactionsInputField.
+	GoalsTable *tview.Table
+
 	// This is the text that is shown below the results table, and contains
 	// status messages, stats about the results, and any other errors that might
 	// come up.
@@ -132,12 +145,63 @@ type FinancePlanner struct {
 	ResultsTable       *tview.Table
 	ResultsForm        *tview.Form
 
+	// Used for prompting the user for values on the results page, such as the
+	// file path when exporting results.
+	ResultsInputField *tview.InputField
+
+	// The state of the incremental search in the transactions table.
+	TransactionsSearch TableSearch
+
+	// The state of the incremental search in the results table.
+	ResultsSearch TableSearch
+
+	// Lists every snapshot in the undo buffer on the undo history page.
+	HistoryTable *tview.Table
+
+	// Shows the changes made in the snapshot that is highlighted in the
+	// HistoryTable.
+	HistoryPreview *tview.TextView
+
+	// Lists every payment of a loan on the amortization schedule page.
+	AmortizationTable *tview.Table
+
+	// Shows the terms and totals of the loan in the AmortizationTable.
+	AmortizationSummary *tview.TextView
+
+	// The input field on the amortization schedule page, used for editing the
+	// loan's extra principal.
+	AmortizationInputField *tview.InputField
+
+	// The ID of the transaction whose loan is shown on the amortization
+	// schedule page.
+	AmortizationTXID string
+
 	// The latest results are stored. For start & end dates that span huge
 	// amounts of time, you may need to think critically about what can be
 	// stored in this, and how garbage collection is a factor. Consider zeroing
 	// out everything where necessary.
 	LatestResults *[]lib.Result
 
+	// The parts of the latest results that the library's results have no
+	// room for, such as the balance of each account.
+	LatestResultsExtra ResultsExtra
+
+	// How the rows of the results table are grouped, which is one of
+	// ResultsGroupings.
+	ResultsGrouping string
+
+	// The grouping that the results table was drilled down from into the
+	// daily rows of one of its groups, which escaping goes back to. Empty when
+	// the table wasn't drilled down.
+	ResultsDrillDownFrom string
+
+	// The label of the group that the results table was drilled down into,
+	// whose days are highlighted, such as "2025-03".
+	ResultsDrillDownLabel string
+
+	// The groups shown in the results table, when it is grouped.
+	ResultsGroups []ResultsGroup
+
 	// There is a hidden fourth page that only shows a modal, typically shown
 	// only for exiting or keyboard echo mode.
 	PromptBox *tview.Modal
@@ -151,6 +215,27 @@ type FinancePlanner struct {
 	// The undo buffer's position is tracked globally via this variable.
 	UndoBufferPos int
 
+	// The serialized form of each profile (by name) as of the last time the
+	// config was loaded or saved. See getUnsavedProfiles.
+	SavedProfiles map[string][]byte
+
+	// The serialized config as of the last time it was loaded or saved. Used
+	// as the common base when merging external changes to the config file.
+	SavedConfig []byte
+
+	// What is known about the config file on disk, used for detecting
+	// external changes to it.
+	ConfigFileState ConfigFileState
+
+	// The time at which each snapshot in the undo buffer was taken. Always
+	// has the same length as UndoBuffer.
+	UndoBufferTimes []time.Time
+
+	// The number of snapshots that have been dropped from the start of the
+	// undo buffer during this session, due to Config.UndoBufferMaxLength or
+	// Config.UndoBufferMaxBytes.
+	UndoBufferEvicted int
+
 	// The name of the configuration file. This will get populated if set by
 	// a flag at runtime, and determines the name of the file that this program
 	// will save configuration changes to. The value can be an absolute or a
@@ -270,8 +355,10 @@ func bootstrap(t map[string]string, conf Config) {
 	FP.ActionBindings = GetAllBoundActions(conf.Keybindings, DefaultMappings)
 
 	initializeUndo(b, conf.DisableGzipCompressionInUndoBuffer)
+	markSaved()
 
 	FP.LastSelection = -1
+	FP.ResultsGrouping = ResultsGroupingDay
 	FP.App = tview.NewApplication()
 
 	FP.Pages = tview.NewPages()
@@ -283,6 +370,8 @@ func bootstrap(t map[string]string, conf Config) {
 	FP.Pages.AddPage(PageProfiles, getProfilesPage(), true, true).
 		AddPage(PageResults, getResultsPage(), true, true).
 		AddPage(PageHelp, FP.HelpTextView, true, true).
+		AddPage(PageHistory, getHistoryPage(), true, true).
+		AddPage(PageAmortization, getAmortizationPage(), true, true).
 		AddPage(PagePrompt, FP.PromptBox, true, true)
 
 	FP.Pages.SwitchToPage(PageProfiles)
@@ -297,6 +386,7 @@ func bootstrap(t map[string]string, conf Config) {
 
 	FP.App.SetFocus(FP.ProfileList)
 
+	promptRestoreUndoHistory()
 	promptKBMode(t)
 
 	FP.App.SetInputCapture(capture)
@@ -339,6 +429,21 @@ func main() {
 
 	processConfig(&FP.Config)
 
+	if b, err := os.ReadFile(FP.FlagConfigFile); err == nil {
+		recordConfigFileState(b)
+	}
+
+	// subcommands run headless and never start the terminal user interface
+	if flag.NArg() > 0 {
+		err = runCommand(flag.Args())
+		// the subcommand's usage has already been printed for -h/--help
+		if err != nil && !errors.Is(err, flag.ErrHelp) {
+			log.Fatalf("%v: %v", FP.T["ErrorFailedToRunCommand"], err.Error())
+		}
+
+		os.Exit(0)
+	}
+
 	theme := FP.Config.Theme
 	if FP.FlagTheme != "" {
 		theme = FP.FlagTheme
@@ -353,7 +458,7 @@ func main() {
 		FP.SelectedProfile = &(FP.Config.Profiles[0])
 	} else {
 		n := Profile{
-			TX:   []lib.TX{lib.GetNewTX(time.Now())},
+			TX:   []TX{getNewTX(time.Now())},
 			Name: FP.T["DefaultNewProfileName"],
 		}
 		FP.Config.Profiles = append(FP.Config.Profiles, n)
@@ -361,8 +466,15 @@ func main() {
 	}
 
 	bootstrap(FP.T, FP.Config)
+	startAutosave()
+	startConfigWatch()
 
 	if err := FP.App.SetRoot(FP.Layout, true).EnableMouse(true).Run(); err != nil {
 		panic(err)
 	}
+
+	err = saveUndoHistory()
+	if err != nil {
+		log.Printf("%v: %v", FP.T["UndoHistorySaveFailed"], err.Error())
+	}
 }
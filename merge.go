@@ -0,0 +1,462 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// This file contains the logic for three-way merging configs. Given a common
+// base, and two versions derived from it ("ours" and "theirs"), profiles are
+// matched by name and transactions are matched by ID. Changes are merged field
+// by field: anything changed on only one side is taken from that side, and
+// any field changed differently on both sides is a conflict.
+//
+// Conflicting transaction fields are resolved in favor of whichever side has
+// the more recent UpdatedAt, falling back to ours. Conflicting profile and
+// config-level fields are always resolved in favor of ours. Anything that was
+// edited on one side and deleted on the other is kept.
+
+// MergeFieldConflict is a single field that was changed differently on both
+// sides of a merge.
+type MergeFieldConflict struct {
+	Field  string
+	Ours   string
+	Theirs string
+
+	// True if the value from theirs was kept, otherwise the value from ours
+	// was kept.
+	KeptTheirs bool
+}
+
+// MergeConflict describes something that was changed differently on both
+// sides of a merge.
+type MergeConflict struct {
+	// The name of the profile that the conflict belongs to. Empty for
+	// config-level settings.
+	Profile string
+
+	// The ID of the conflicting transaction. Empty for conflicts in the
+	// profile's own fields, or in config-level settings.
+	ID string
+
+	// The name of the conflicting transaction, if ID is set.
+	Name string
+
+	// True if this was edited on one side and deleted on the other, in which
+	// case the edited version was kept.
+	Deleted bool
+
+	// The conflicting fields, if both sides edited it.
+	Fields []MergeFieldConflict
+}
+
+// String renders the conflict for display, such as "Default / Rent (abc)".
+func (c MergeConflict) String() string {
+	switch {
+	case c.Profile == "":
+		return FP.T["SnapshotSettings"]
+	case c.ID == "":
+		return fmt.Sprintf("%v %v", FP.T["SnapshotProfile"], c.Profile)
+	default:
+		return fmt.Sprintf("%v / %v (%v)", c.Profile, c.Name, c.ID)
+	}
+}
+
+// yamlEqual returns true if a and b serialize to the same yaml.
+func yamlEqual(a, b any) bool {
+	ab, err := yaml.Marshal(a)
+	if err != nil {
+		return false
+	}
+
+	bb, err := yaml.Marshal(b)
+	if err != nil {
+		return false
+	}
+
+	return bytes.Equal(ab, bb)
+}
+
+// toYAMLMap converts v into a generic map of its yaml fields.
+func toYAMLMap(v any) (map[string]any, error) {
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal: %w", err)
+	}
+
+	m := map[string]any{}
+
+	err = yaml.Unmarshal(b, &m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal: %w", err)
+	}
+
+	return m, nil
+}
+
+// mergeFields performs a three-way merge of every yaml field of a single
+// value. Conflicting fields take the value from theirs if preferTheirs is
+// true, otherwise from ours.
+func mergeFields[T any](base, ours, theirs T, preferTheirs bool) (T, []MergeFieldConflict) {
+	if yamlEqual(ours, theirs) {
+		return ours, nil
+	}
+
+	bm, errB := toYAMLMap(base)
+	om, errO := toYAMLMap(ours)
+	tm, errT := toYAMLMap(theirs)
+
+	if errB != nil || errO != nil || errT != nil {
+		// not expected to happen, but if it does, treat the whole value as a
+		// single conflicting field
+		return ours, []MergeFieldConflict{{}}
+	}
+
+	keys := []string{}
+
+	for _, m := range []map[string]any{bm, om, tm} {
+		for k := range m {
+			if !slices.Contains(keys, k) {
+				keys = append(keys, k)
+			}
+		}
+	}
+
+	slices.Sort(keys)
+
+	merged := map[string]any{}
+	conflicts := []MergeFieldConflict{}
+
+	for _, k := range keys {
+		b, o, t := bm[k], om[k], tm[k]
+
+		switch {
+		case yamlEqual(o, t), yamlEqual(b, t):
+			merged[k] = o
+		case yamlEqual(b, o):
+			merged[k] = t
+		default:
+			merged[k] = o
+			if preferTheirs {
+				merged[k] = t
+			}
+
+			conflicts = append(conflicts, MergeFieldConflict{
+				Field:      k,
+				Ours:       formatSnapshotValue(k, o),
+				Theirs:     formatSnapshotValue(k, t),
+				KeptTheirs: preferTheirs,
+			})
+		}
+	}
+
+	var result T
+
+	b, err := yaml.Marshal(merged)
+	if err == nil {
+		err = yaml.Unmarshal(b, &result)
+	}
+
+	if err != nil {
+		return ours, []MergeFieldConflict{{}}
+	}
+
+	return result, conflicts
+}
+
+// mergeOptional performs a three-way merge of a value that may be missing from
+// any side (such as a transaction that was added or deleted). Returns the
+// merged value, whether it should exist at all, any conflicting fields, and
+// whether it was edited on one side but deleted on the other.
+func mergeOptional[T any](base, ours, theirs *T, preferTheirs bool) (*T, bool, []MergeFieldConflict, bool) {
+	switch {
+	case ours != nil && theirs != nil:
+		var zero T
+
+		if base == nil {
+			// added on both sides
+			base = &zero
+		}
+
+		merged, conflicts := mergeFields(*base, *ours, *theirs, preferTheirs)
+
+		return &merged, true, conflicts, false
+	case ours != nil:
+		// deleted on their side, or added on ours
+		if base == nil || yamlEqual(*base, *ours) {
+			return ours, base == nil, nil, false
+		}
+
+		// edited on our side but deleted on theirs
+		return ours, true, nil, true
+	case theirs != nil:
+		// deleted on our side, or added on theirs
+		if base == nil || yamlEqual(*base, *theirs) {
+			return theirs, base == nil, nil, false
+		}
+
+		// edited on their side but deleted on ours
+		return theirs, true, nil, true
+	default:
+		return nil, false, nil, false
+	}
+}
+
+// getTXKeys returns the keys that the transactions are matched by, which are
+// their IDs. Transactions that share an ID, including an empty one, are told
+// apart by how many before them have the same ID, so that the n'th of them is
+// matched with the n'th of them on the other sides, and none are lost.
+func getTXKeys(txs []TX) []string {
+	keys := make([]string, len(txs))
+	seen := make(map[string]int, len(txs))
+
+	for i := range txs {
+		id := txs[i].ID
+		keys[i] = id
+
+		if n := seen[id]; n > 0 {
+			keys[i] = fmt.Sprintf("%v\x00%v", id, n)
+		}
+
+		seen[id]++
+	}
+
+	return keys
+}
+
+// indexTX returns a lookup of transactions by the keys from getTXKeys.
+func indexTX(txs []TX) map[string]*TX {
+	keys := getTXKeys(txs)
+	index := make(map[string]*TX, len(txs))
+
+	for i := range txs {
+		index[keys[i]] = &(txs[i])
+	}
+
+	return index
+}
+
+// mergeTX merges a single transaction. UpdatedAt decides which side wins any
+// conflicting fields, and the merged transaction keeps the most recent
+// UpdatedAt of the two.
+func mergeTX(base, ours, theirs *TX) (*TX, bool, []MergeFieldConflict, bool) {
+	if ours == nil || theirs == nil {
+		return mergeOptional(base, ours, theirs, false)
+	}
+
+	updatedAt := ours.UpdatedAt
+	preferTheirs := theirs.UpdatedAt.After(ours.UpdatedAt)
+
+	if preferTheirs {
+		updatedAt = theirs.UpdatedAt
+	}
+
+	// UpdatedAt itself is only a hint, so it should never conflict
+	o, t := *ours, *theirs
+	o.UpdatedAt, t.UpdatedAt = time.Time{}, time.Time{}
+
+	var b *TX
+
+	if base != nil {
+		bc := *base
+		bc.UpdatedAt = time.Time{}
+		b = &bc
+	}
+
+	tx, keep, conflicts, deleted := mergeOptional(b, &o, &t, preferTheirs)
+	tx.UpdatedAt = updatedAt
+
+	return tx, keep, conflicts, deleted
+}
+
+// mergeTransactions merges a profile's transactions by ID (see getTXKeys).
+// The order follows ours, with transactions that only exist in theirs
+// appended at the end, in their order.
+func mergeTransactions(profile string, base, ours, theirs []TX) ([]TX, []MergeConflict) {
+	baseIndex := indexTX(base)
+	ourIndex := indexTX(ours)
+	theirIndex := indexTX(theirs)
+
+	merged := []TX{}
+	conflicts := []MergeConflict{}
+
+	keys := getTXKeys(ours)
+
+	for _, key := range getTXKeys(theirs) {
+		if _, ok := ourIndex[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+
+	for _, key := range keys {
+		tx, keep, fields, deleted := mergeTX(baseIndex[key], ourIndex[key], theirIndex[key])
+		if len(fields) > 0 || deleted {
+			conflicts = append(conflicts, MergeConflict{
+				Profile: profile,
+				ID:      tx.ID,
+				Name:    tx.Name,
+				Deleted: deleted,
+				Fields:  fields,
+			})
+		}
+
+		if keep {
+			merged = append(merged, *tx)
+		}
+	}
+
+	return merged, conflicts
+}
+
+// indexProfiles returns a lookup of profiles by name.
+func indexProfiles(profiles []Profile) map[string]*Profile {
+	index := make(map[string]*Profile, len(profiles))
+
+	for i := range profiles {
+		if _, ok := index[profiles[i].Name]; !ok {
+			index[profiles[i].Name] = &(profiles[i])
+		}
+	}
+
+	return index
+}
+
+// withoutTX returns a copy of the profile without any transactions, so that
+// the profile's own fields can be compared separately.
+func withoutTX(p *Profile) *Profile {
+	if p == nil {
+		return nil
+	}
+
+	c := *p
+	c.TX = nil
+
+	return &c
+}
+
+// withoutProfiles returns a copy of the config without any profiles, so that
+// the config-level settings can be compared separately.
+func withoutProfiles(c Config) Config {
+	c.Profiles = nil
+
+	return c
+}
+
+// mergeConfigs performs a three-way merge of ours and theirs, which were both
+// derived from base. Profiles are matched by name and transactions by ID.
+// Returns the merged config and any conflicts, which have already been
+// resolved as described at the top of this file.
+func mergeConfigs(base, ours, theirs Config) (Config, []MergeConflict) {
+	conflicts := []MergeConflict{}
+
+	merged, fields := mergeFields(withoutProfiles(base), withoutProfiles(ours), withoutProfiles(theirs), false)
+	if len(fields) > 0 {
+		conflicts = append(conflicts, MergeConflict{Fields: fields})
+	}
+
+	baseIndex := indexProfiles(base.Profiles)
+	ourIndex := indexProfiles(ours.Profiles)
+	theirIndex := indexProfiles(theirs.Profiles)
+
+	names := []string{}
+	for i := range ours.Profiles {
+		names = append(names, ours.Profiles[i].Name)
+	}
+
+	for i := range theirs.Profiles {
+		if _, ok := ourIndex[theirs.Profiles[i].Name]; !ok {
+			names = append(names, theirs.Profiles[i].Name)
+		}
+	}
+
+	merged.Profiles = []Profile{}
+
+	for _, name := range names {
+		b, o, t := baseIndex[name], ourIndex[name], theirIndex[name]
+
+		// added or deleted on one side; a profile that was deleted on one
+		// side but edited on the other is kept
+		if o == nil || t == nil {
+			p, keep, _, deleted := mergeOptional(b, o, t, false)
+			if deleted {
+				conflicts = append(conflicts, MergeConflict{Profile: name, Deleted: true})
+			}
+
+			if keep {
+				merged.Profiles = append(merged.Profiles, *p)
+			}
+
+			continue
+		}
+
+		p, _, fields, _ := mergeOptional(withoutTX(b), withoutTX(o), withoutTX(t), false)
+		if len(fields) > 0 {
+			conflicts = append(conflicts, MergeConflict{Profile: name, Fields: fields})
+		}
+
+		var btx []TX
+		if b != nil {
+			btx = b.TX
+		}
+
+		txs, txConflicts := mergeTransactions(name, btx, o.TX, t.TX)
+		conflicts = append(conflicts, txConflicts...)
+
+		p.TX = txs
+		merged.Profiles = append(merged.Profiles, *p)
+	}
+
+	return merged, conflicts
+}
+
+// writeMergeReport writes the conflicts from a merge as a markdown report, so
+// that they can be reviewed after the fact.
+func writeMergeReport(w io.Writer, conflicts []MergeConflict) error {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("# %v\n\n%v: %v\n", FP.T["MergeReportTitle"], FP.T["MergeReportConflicts"], len(conflicts)))
+
+	for _, c := range conflicts {
+		sb.WriteString(fmt.Sprintf("\n## %v\n\n", c.String()))
+
+		if c.Deleted {
+			sb.WriteString(fmt.Sprintf("%v\n", FP.T["MergeReportDeleted"]))
+
+			continue
+		}
+
+		sb.WriteString(fmt.Sprintf("| %v | %v | %v | %v |\n| --- | --- | --- | --- |\n",
+			FP.T["MergeReportField"],
+			FP.T["MergeReportOurs"],
+			FP.T["MergeReportTheirs"],
+			FP.T["MergeReportKept"],
+		))
+
+		for _, f := range c.Fields {
+			kept := FP.T["MergeReportOurs"]
+			if f.KeptTheirs {
+				kept = FP.T["MergeReportTheirs"]
+			}
+
+			sb.WriteString(fmt.Sprintf("| %v | %v | %v | %v |\n",
+				escapeMarkdownCell(f.Field),
+				escapeMarkdownCell(f.Ours),
+				escapeMarkdownCell(f.Theirs),
+				kept,
+			))
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	if err != nil {
+		return fmt.Errorf("failed to write merge report: %w", err)
+	}
+
+	return nil
+}
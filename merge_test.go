@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+// getTestTX returns a transaction with the given ID, name and amount, last
+// updated the given number of days after the start of 2025.
+func getTestTX(id, name string, amount, updated int) TX {
+	tx := TX{}
+	tx.ID = id
+	tx.Name = name
+	tx.Amount = amount
+	tx.UpdatedAt = time.Date(2025, time.January, 1+updated, 0, 0, 0, 0, time.UTC)
+
+	return tx
+}
+
+// getTestConfig returns a config with a single profile with the given
+// transactions.
+func getTestConfig(txs ...TX) Config {
+	return Config{Profiles: []Profile{{Name: "test", TX: txs}}}
+}
+
+// getTestTXSummaries returns each transaction as "name amount", so that the
+// merged transactions can be compared regardless of their other fields.
+func getTestTXSummaries(txs []TX) []string {
+	summaries := []string{}
+	for i := range txs {
+		summaries = append(summaries, fmt.Sprintf("%v %v", txs[i].Name, txs[i].Amount))
+	}
+
+	return summaries
+}
+
+func TestMergeConfigs(t *testing.T) {
+	a := getTestTX("a", "rent", -100000, 0)
+	b := getTestTX("b", "salary", 300000, 0)
+
+	tests := []struct {
+		name   string
+		base   Config
+		ours   Config
+		theirs Config
+		want   []string
+		// the number of conflicts, and how many of them were edited on one
+		// side and deleted on the other
+		wantConflicts int
+		wantDeleted   int
+		// whether the conflicting fields kept the value from theirs
+		wantKeptTheirs bool
+	}{
+		{
+			name:   "unchanged",
+			base:   getTestConfig(a, b),
+			ours:   getTestConfig(a, b),
+			theirs: getTestConfig(a, b),
+			want:   []string{"rent -100000", "salary 300000"},
+		},
+		{
+			name:   "different transactions changed on each side",
+			base:   getTestConfig(a, b),
+			ours:   getTestConfig(getTestTX("a", "rent", -120000, 1), b),
+			theirs: getTestConfig(a, getTestTX("b", "salary", 310000, 1)),
+			want:   []string{"rent -120000", "salary 310000"},
+		},
+		{
+			name:   "different fields changed on each side",
+			base:   getTestConfig(a),
+			ours:   getTestConfig(getTestTX("a", "rent", -120000, 1)),
+			theirs: getTestConfig(getTestTX("a", "mortgage", -100000, 2)),
+			want:   []string{"mortgage -120000"},
+		},
+		{
+			name:           "conflict where theirs is newer",
+			base:           getTestConfig(a),
+			ours:           getTestConfig(getTestTX("a", "rent", -120000, 1)),
+			theirs:         getTestConfig(getTestTX("a", "rent", -130000, 2)),
+			want:           []string{"rent -130000"},
+			wantConflicts:  1,
+			wantKeptTheirs: true,
+		},
+		{
+			name:          "conflict where ours is newer",
+			base:          getTestConfig(a),
+			ours:          getTestConfig(getTestTX("a", "rent", -120000, 2)),
+			theirs:        getTestConfig(getTestTX("a", "rent", -130000, 1)),
+			want:          []string{"rent -120000"},
+			wantConflicts: 1,
+		},
+		{
+			name:   "added on both sides",
+			base:   getTestConfig(a),
+			ours:   getTestConfig(a, b),
+			theirs: getTestConfig(a, getTestTX("c", "bonus", 50000, 1)),
+			want:   []string{"rent -100000", "salary 300000", "bonus 50000"},
+		},
+		{
+			name:   "deleted on one side",
+			base:   getTestConfig(a, b),
+			ours:   getTestConfig(a, b),
+			theirs: getTestConfig(b),
+			want:   []string{"salary 300000"},
+		},
+		{
+			name:          "deleted on one side and edited on the other",
+			base:          getTestConfig(a, b),
+			ours:          getTestConfig(getTestTX("a", "rent", -120000, 1), b),
+			theirs:        getTestConfig(b),
+			want:          []string{"rent -120000", "salary 300000"},
+			wantConflicts: 1,
+			wantDeleted:   1,
+		},
+		{
+			name:   "empty IDs",
+			base:   getTestConfig(getTestTX("", "x", 1, 0), getTestTX("", "y", 2, 0)),
+			ours:   getTestConfig(getTestTX("", "x", 1, 0), getTestTX("", "y", 2, 0)),
+			theirs: getTestConfig(getTestTX("", "x", 1, 0), getTestTX("", "y", 2, 0)),
+			want:   []string{"x 1", "y 2"},
+		},
+		{
+			name:   "duplicate IDs edited on one side",
+			base:   getTestConfig(getTestTX("d", "x", 1, 0), getTestTX("d", "y", 2, 0)),
+			ours:   getTestConfig(getTestTX("d", "x", 1, 0), getTestTX("d", "y", 2, 0)),
+			theirs: getTestConfig(getTestTX("d", "x", 1, 0), getTestTX("d", "y", 3, 1)),
+			want:   []string{"x 1", "y 3"},
+		},
+		{
+			name:   "duplicate IDs added on one side",
+			base:   getTestConfig(getTestTX("d", "x", 1, 0)),
+			ours:   getTestConfig(getTestTX("d", "x", 1, 0)),
+			theirs: getTestConfig(getTestTX("d", "x", 1, 0), getTestTX("d", "y", 2, 1)),
+			want:   []string{"x 1", "y 2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, conflicts := mergeConfigs(tt.base, tt.ours, tt.theirs)
+
+			if len(merged.Profiles) != 1 {
+				t.Fatalf("got %v profiles, want 1", len(merged.Profiles))
+			}
+
+			got := getTestTXSummaries(merged.Profiles[0].TX)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got transactions %v, want %v", got, tt.want)
+			}
+
+			if len(conflicts) != tt.wantConflicts {
+				t.Fatalf("got %v conflicts, want %v", len(conflicts), tt.wantConflicts)
+			}
+
+			deleted := 0
+
+			for _, c := range conflicts {
+				if c.Deleted {
+					deleted++
+				}
+
+				for _, f := range c.Fields {
+					if f.KeptTheirs != tt.wantKeptTheirs {
+						t.Errorf("got KeptTheirs %v for %v, want %v", f.KeptTheirs, f.Field, tt.wantKeptTheirs)
+					}
+				}
+			}
+
+			if deleted != tt.wantDeleted {
+				t.Errorf("got %v deleted conflicts, want %v", deleted, tt.wantDeleted)
+			}
+		})
+	}
+}
@@ -1,30 +1,116 @@
 package main
 
 import (
+	"maps"
+	"slices"
+	"time"
+
 	lib "github.com/charles-m-knox/finance-planner-lib"
 )
 
+// TX is a recurring transaction definition. It extends the library's
+// transaction with settings that are handled by this application before
+// results are generated, and is stored inline in the config, so configs
+// without these settings remain compatible.
+type TX struct {
+	lib.TX `yaml:",inline"`
+	// determines whether occurrences that land on a weekend or holiday are
+	// moved to the previous or next business day; see the Roll* constants.
+	Roll string `yaml:"roll,omitempty"`
+	// the maximum number of times that the transaction occurs, counting from
+	// its start date, such as for installment plans. 0 means unlimited.
+	Occurrences int `yaml:"occurrences,omitempty"`
+	// step changes to the amount on given dates, such as raises or price
+	// hikes; see AmountChange.
+	AmountChanges []AmountChange `yaml:"amountChanges,omitempty"`
+	// a percentage by which the amount grows every year, such as for
+	// inflation. It compounds from the transaction's start date, or from the
+	// date of the latest amount change.
+	Escalation float64 `yaml:"escalation,omitempty"`
+	// the name of the profile's account that the transaction belongs to;
+	// empty for the main account. See Account.
+	Account string `yaml:"account,omitempty"`
+	// if set, the transaction is a transfer of its amount from Account to
+	// the account with this name.
+	TransferTo string `yaml:"transferTo,omitempty"`
+	// if set, the transaction makes the payments of this loan; see Loan.
+	Loan Loan `yaml:"loan,omitempty"`
+	// how much the amount varies between occurrences, which is only used by
+	// the Monte Carlo projection; see Uncertainty.
+	Uncertainty Uncertainty `yaml:"uncertainty,omitempty"`
+}
+
+// getNewTX returns a new transaction with the library's defaults.
+func getNewTX(t time.Time) TX {
+	return TX{TX: lib.GetNewTX(t)}
+}
+
+// duplicateTX returns a copy of the transaction with all of its settings, but
+// with a new ID and creation time, so that it is treated as a new transaction.
+func duplicateTX(tx *TX, t time.Time) TX {
+	newTX := *tx
+	newTX.ID = getNewTX(t).ID
+	newTX.CreatedAt = t
+	newTX.UpdatedAt = t
+	newTX.Selected = false
+	newTX.Weekdays = maps.Clone(tx.Weekdays)
+	newTX.AmountChanges = slices.Clone(tx.AmountChanges)
+
+	return newTX
+}
+
 type Profile struct {
-	TX              []lib.TX `yaml:"transactions"`
-	Name            string   `yaml:"name"`
-	Modified        bool     `yaml:"-"`
-	SelectedRow     int      `yaml:"selectedRow"`
-	SelectedColumn  int      `yaml:"selectedColumn"`
-	StartingBalance string   `yaml:"startingBalance"`
-	StartDay        string   `yaml:"startDay"`
-	StartMonth      string   `yaml:"startMonth"`
-	StartYear       string   `yaml:"startYear"`
-	EndDay          string   `yaml:"endDay"`
-	EndMonth        string   `yaml:"endMonth"`
-	EndYear         string   `yaml:"endYear"`
+	TX              []TX   `yaml:"transactions"`
+	Name            string `yaml:"name"`
+	Modified        bool   `yaml:"-"`
+	SelectedRow     int    `yaml:"selectedRow"`
+	SelectedColumn  int    `yaml:"selectedColumn"`
+	StartingBalance string `yaml:"startingBalance"`
+	StartDay        string `yaml:"startDay"`
+	StartMonth      string `yaml:"startMonth"`
+	StartYear       string `yaml:"startYear"`
+	EndDay          string `yaml:"endDay"`
+	EndMonth        string `yaml:"endMonth"`
+	EndYear         string `yaml:"endYear"`
+	// the annual percentage yield, as a percentage, that is earned on a
+	// positive balance in the results
+	APY float64 `yaml:"apy,omitempty"`
+	// the annual percentage rate, as a percentage, that is charged on a
+	// negative balance in the results
+	APR float64 `yaml:"apr,omitempty"`
+	// how often interest is compounded: DAILY, MONTHLY (the default) or
+	// YEARLY
+	InterestCompounding string `yaml:"interestCompounding,omitempty"`
+	// accounts that the profile's balance is split between, in addition to
+	// its main account, which has the starting balance above
+	Accounts []Account `yaml:"accounts,omitempty"`
+	// the number of Monte Carlo simulations to run alongside the results; 0
+	// disables the Monte Carlo projection
+	Simulations int `yaml:"simulations,omitempty"`
+	// the seed of the Monte Carlo simulations, so that the same projection
+	// can be reproduced
+	SimulationSeed int64 `yaml:"simulationSeed,omitempty"`
+	// the lowest balance that the results should stay at or above, such as
+	// $500.00; empty for none. Days below it are highlighted in the results.
+	MinimumBalance string `yaml:"minimumBalance,omitempty"`
+	// amounts that the balance, or one of the accounts, should reach; see
+	// getGoalsProgress
+	Goals []Goal `yaml:"goals,omitempty"`
 }
 
 type Config struct {
-	Keybindings         map[string][]string `yaml:"keybindings"`
-	Profiles            []Profile           `yaml:"profiles"`
-	UndoBufferMaxLength int                 `yaml:"undoBufferMaxLength"`
-	Version             string              `yaml:"version"`
-	Theme               string              `yaml:"theme"`
+	Keybindings map[string][]string `yaml:"keybindings"`
+	Profiles    []Profile           `yaml:"profiles"`
+	// The maximum number of snapshots kept in the undo buffer. When exceeded,
+	// the oldest snapshots are dropped. 0 means unlimited.
+	UndoBufferMaxLength int `yaml:"undoBufferMaxLength"`
+	// The maximum combined size, in bytes, of all snapshots kept in the undo
+	// buffer (after compression, if enabled). When exceeded, the oldest
+	// snapshots are dropped, but the latest snapshot is always kept. 0 means
+	// unlimited.
+	UndoBufferMaxBytes int    `yaml:"undoBufferMaxBytes"`
+	Version            string `yaml:"version"`
+	Theme              string `yaml:"theme"`
 	// if true, results calculations will be faster for large date ranges,
 	// as the terminal will not need to periodically re-render the page to
 	// show status/progress messages for its work-in-progress calculations
@@ -34,6 +120,30 @@ type Config struct {
 	// system that struggles with gzip somehow, you can disable this feature
 	// here at the cost of using more memory.
 	DisableGzipCompressionInUndoBuffer bool `yaml:"disableGzipCompressionInUndoBuffer"`
+	// if true, the undo buffer will not be written to the XDG state directory
+	// when saving or exiting, and no previous undo history will be offered
+	// for restoration on startup.
+	DisableUndoHistory bool `yaml:"disableUndoHistory"`
+	// The number of timestamped backups of the config file to keep next to
+	// it. A backup of the previous contents is made every time the config is
+	// saved, and the oldest backups are deleted. Defaults to
+	// DefaultBackupCount when unset; 0 disables backups. See getBackupCount.
+	BackupCount *int `yaml:"backupCount,omitempty"`
+	// The number of seconds between automatic saves, which only happen when
+	// there are unsaved changes. 0 disables saving on an interval.
+	AutosaveInterval int `yaml:"autosaveInterval"`
+	// if true, the config is saved every time a change is made.
+	AutosaveOnChange bool `yaml:"autosaveOnChange"`
+	// if true, the config file will not be checked for changes made outside
+	// of this application while it is running.
+	DisableConfigFileWatch bool `yaml:"disableConfigFileWatch"`
+	// Dates formatted as YYYY-MM-DD that are not considered business days,
+	// such as public holidays. Holidays that fall on the same date every year
+	// can be formatted as MM-DD instead.
+	Holidays []string `yaml:"holidays"`
+	// if true, adding a transaction immediately adds a blank one instead of
+	// offering a choice of templates for common schedules first.
+	DisableTransactionTemplates bool `yaml:"disableTransactionTemplates"`
 }
 
 type TableCell struct {
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDuplicateTX(t *testing.T) {
+	created := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	tx := getNewTX(created)
+	tx.Amount = -150000
+	tx.Name = "Mortgage"
+	tx.Selected = true
+	tx.Roll = RollNext
+	tx.Occurrences = 360
+	tx.AmountChanges = []AmountChange{{Date: "2055-01-01", Amount: -149000}}
+	tx.Escalation = 2
+	tx.Account = "Checking"
+	tx.TransferTo = "Savings"
+	tx.Loan = Loan{Principal: 20000000, Rate: 6, Term: 360}
+	tx.Uncertainty = Uncertainty{StdDev: 100}
+
+	got := duplicateTX(&tx, now)
+
+	if got.ID == "" || got.ID == tx.ID {
+		t.Errorf("got ID %q, want a new one", got.ID)
+	}
+
+	if !got.CreatedAt.Equal(now) || !got.UpdatedAt.Equal(now) {
+		t.Errorf("got created %v and updated %v, want %v", got.CreatedAt, got.UpdatedAt, now)
+	}
+
+	if got.Selected {
+		t.Errorf("got a selected duplicate, want it unselected")
+	}
+
+	// everything else is kept
+	want := tx
+	want.ID = got.ID
+	want.CreatedAt = got.CreatedAt
+	want.UpdatedAt = got.UpdatedAt
+	want.Selected = false
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	// and is not shared with the original
+	got.Weekdays[0] = !got.Weekdays[0]
+	got.AmountChanges[0].Amount = 0
+
+	if got.Weekdays[0] == tx.Weekdays[0] || tx.AmountChanges[0].Amount == 0 {
+		t.Errorf("got a duplicate that shares its weekdays or amount changes with the original")
+	}
+}
@@ -0,0 +1,354 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	lib "github.com/charles-m-knox/finance-planner-lib"
+)
+
+// This file contains the Monte Carlo projection, which models transactions
+// whose amounts vary from one occurrence to the next, such as utilities and
+// groceries. Each simulation draws a random amount for every occurrence of
+// those transactions, and the balances of all simulations are summarized as
+// percentile bands, along with how likely the balance is to go negative.
+
+const (
+	// The maximum number of simulations, which bounds the memory needed to
+	// hold every simulation's balance on every day.
+	MaxSimulations = 2000
+
+	// The prefix of a transaction's standard deviation in its amount
+	// schedule, such as "sd $15.00".
+	UncertaintyStdDevPrefix = "sd "
+
+	// The separator between the lowest and highest amounts of a
+	// transaction's range in its amount schedule, such as
+	// "$-80.00..$-150.00".
+	UncertaintyRangeSeparator = ".."
+)
+
+// Uncertainty is how much a transaction's amount varies between occurrences,
+// either within a range or by a standard deviation. The amount of every
+// occurrence is still the transaction's amount in the regular results; only
+// the Monte Carlo projection draws random amounts.
+type Uncertainty struct {
+	// the lowest and highest amounts, in cents, that an occurrence can have,
+	// which are equally likely. The range moves with the transaction's amount
+	// schedule.
+	Min int `yaml:"min,omitempty"`
+	Max int `yaml:"max,omitempty"`
+	// the standard deviation, in cents, of a normal distribution around the
+	// amount of each occurrence
+	StdDev int `yaml:"stdDev,omitempty"`
+}
+
+// MonteCarloDay summarizes the balances of all simulations at the end of a
+// day of the results.
+type MonteCarloDay struct {
+	P10 int `json:"p10"`
+	P50 int `json:"p50"`
+	P90 int `json:"p90"`
+	// the fraction of simulations, from 0 to 1, whose balance went negative
+	// on or before this day
+	NegativeProbability float64 `json:"negativeProbability"`
+}
+
+// uncertainOccurrence is a single occurrence of an uncertain transaction
+// within the results.
+type uncertainOccurrence struct {
+	// the index of the occurrence's day in the results
+	Day int
+	// the transaction's own amount, which a range is relative to
+	Amount      int
+	Uncertainty Uncertainty
+	// see getUncertainSign
+	Sign int
+}
+
+// hasUncertainty returns true if the transaction's amount varies between
+// occurrences.
+func hasUncertainty(tx *TX) bool {
+	return tx.Uncertainty.StdDev > 0 || tx.Uncertainty.Min != tx.Uncertainty.Max
+}
+
+// getUncertainOffset draws the difference between a random amount for an
+// occurrence and the amount that it has in the regular results.
+func getUncertainOffset(o *uncertainOccurrence, r *rand.Rand) int {
+	u := o.Uncertainty
+	if u.StdDev > 0 {
+		return int(math.Round(r.NormFloat64() * float64(u.StdDev)))
+	}
+
+	lo, hi := min(u.Min, u.Max), max(u.Min, u.Max)
+
+	return lo + r.IntN(hi-lo+1) - o.Amount
+}
+
+// getSign returns 1 for positive amounts, -1 for negative amounts and 0
+// otherwise.
+func getSign(a int) int {
+	switch {
+	case a > 0:
+		return 1
+	case a < 0:
+		return -1
+	}
+
+	return 0
+}
+
+// getUncertainSign returns how a larger amount of the transaction changes the
+// balance of the results, which only includes cash accounts, in the same way
+// as getTotalTransactions: 1 if it raises it, -1 if it lowers it, and 0 if it
+// doesn't change it.
+func getUncertainSign(p *Profile, tx *TX) int {
+	from := isCash(p, tx.Account)
+
+	switch {
+	case !isTransfer(tx) && from:
+		return 1
+	case !isTransfer(tx):
+		return 0
+	case from && !isCash(p, tx.TransferTo):
+		return -getSign(tx.Amount)
+	case !from && isCash(p, tx.TransferTo):
+		return getSign(tx.Amount)
+	}
+
+	return 0
+}
+
+// getUncertainOccurrences returns every occurrence of the uncertain
+// transactions that changes the balance of the results, on the same days that
+// prepareTransactions places them.
+func getUncertainOccurrences(
+	p *Profile,
+	results []lib.Result,
+	transactions []TX,
+	start, end time.Time,
+	holidays HolidayCalendar,
+) ([]uncertainOccurrence, error) {
+	days := make(map[string]int, len(results))
+	for i := range results {
+		days[lib.GetNowDateString(results[i].Date)] = i
+	}
+
+	occurrences := []uncertainOccurrence{}
+
+	for i := range transactions {
+		tx := &transactions[i]
+
+		sign := getUncertainSign(p, tx)
+		if !tx.Active || !hasUncertainty(tx) || sign == 0 {
+			continue
+		}
+
+		from, to := start.AddDate(0, 0, -RollWindow), end.AddDate(0, 0, RollWindow)
+
+		dates, err := getTXOccurrences(tx, start, from, to, holidays)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %w", tx.Name, err)
+		}
+
+		for _, d := range dates {
+			day, ok := days[lib.GetNowDateString(rollDate(d, tx.Roll, holidays))]
+			if !ok {
+				continue
+			}
+
+			occurrences = append(occurrences, uncertainOccurrence{
+				Day:         day,
+				Amount:      tx.Amount,
+				Uncertainty: tx.Uncertainty,
+				Sign:        sign,
+			})
+		}
+	}
+
+	return occurrences, nil
+}
+
+// simulate runs the k'th simulation, which draws a random amount for every
+// uncertain occurrence, and writes its balance at the end of each day into
+// balances[i][k]. It returns the index of the first day on which the balance
+// is negative, or -1 if it never is.
+func simulate(k int, seed int64, results []lib.Result, occurrences []uncertainOccurrence, balances [][]int) int {
+	r := rand.New(rand.NewPCG(uint64(seed), uint64(k)))
+	offsets := make([]int, len(results))
+
+	for i := range occurrences {
+		offsets[occurrences[i].Day] += occurrences[i].Sign * getUncertainOffset(&occurrences[i], r)
+	}
+
+	firstNegative := -1
+	offset := 0
+
+	for i := range results {
+		offset += offsets[i]
+		balances[i][k] = results[i].Balance + offset
+
+		if firstNegative < 0 && balances[i][k] < 0 {
+			firstNegative = i
+		}
+	}
+
+	return firstNegative
+}
+
+// getPercentile returns the p'th percentile of the sorted values, using the
+// nearest rank.
+func getPercentile(sorted []int, p int) int {
+	i := int(math.Ceil(float64(p)/100*float64(len(sorted)))) - 1
+
+	return sorted[min(max(i, 0), len(sorted)-1)]
+}
+
+// runMonteCarlo runs the profile's number of simulations in parallel and
+// summarizes their balances on each day of the results. The simulations
+// start from the results, including their interest, which is not recalculated
+// for each simulation. The same seed always produces the same projection.
+func runMonteCarlo(
+	p *Profile,
+	results []lib.Result,
+	transactions []TX,
+	start, end time.Time,
+	holidays HolidayCalendar,
+) ([]MonteCarloDay, error) {
+	n := min(p.Simulations, MaxSimulations)
+	if n < 1 || len(results) == 0 {
+		return nil, nil
+	}
+
+	occurrences, err := getUncertainOccurrences(p, results, transactions, start, end, holidays)
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make([][]int, len(results))
+	for i := range balances {
+		balances[i] = make([]int, n)
+	}
+
+	firstNegative := make([]int, n)
+	workers := min(runtime.NumCPU(), n)
+
+	var wg sync.WaitGroup
+
+	for w := range workers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			// each simulation has its own source, so that the results don't
+			// depend on how the simulations are split between workers
+			for k := w; k < n; k += workers {
+				firstNegative[k] = simulate(k, p.SimulationSeed, results, occurrences, balances)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	negativeByDay := make([]int, len(results))
+
+	for _, day := range firstNegative {
+		if day >= 0 {
+			negativeByDay[day]++
+		}
+	}
+
+	days := make([]MonteCarloDay, len(results))
+	negative := 0
+
+	for i := range balances {
+		slices.Sort(balances[i])
+
+		negative += negativeByDay[i]
+
+		days[i] = MonteCarloDay{
+			P10:                 getPercentile(balances[i], 10),
+			P50:                 getPercentile(balances[i], 50),
+			P90:                 getPercentile(balances[i], 90),
+			NegativeProbability: float64(negative) / float64(n),
+		}
+	}
+
+	return days, nil
+}
+
+// formatProbability formats a probability from 0 to 1 as a percentage, such
+// as "12.5%".
+func formatProbability(probability float64) string {
+	return fmt.Sprintf("%v%%", strconv.FormatFloat(probability*100, 'f', 1, 64))
+}
+
+// getMonteCarloStats returns a summary of the Monte Carlo projection as text,
+// to be shown after the other statistics about the results.
+func getMonteCarloStats(p *Profile, days []MonteCarloDay) string {
+	last := days[len(days)-1]
+
+	return fmt.Sprintf("%v: %v (%v %v)\n%v: %v / %v / %v\n%v: %v",
+		FP.T["ResultsStatsSimulations"],
+		min(p.Simulations, MaxSimulations),
+		FP.T["ResultsStatsSimulationSeed"],
+		p.SimulationSeed,
+		FP.T["ResultsStatsFinalPercentiles"],
+		lib.FormatAsCurrency(last.P10),
+		lib.FormatAsCurrency(last.P50),
+		lib.FormatAsCurrency(last.P90),
+		FP.T["ResultsStatsNegativeProbability"],
+		formatProbability(last.NegativeProbability),
+	)
+}
+
+// formatUncertainty formats the transaction's uncertainty as an entry of its
+// amount schedule, such as "sd $15.00" or "$-80.00..$-150.00", or returns ""
+// if its amount doesn't vary.
+func formatUncertainty(tx *TX) string {
+	switch u := tx.Uncertainty; {
+	case u.StdDev > 0:
+		return fmt.Sprintf("%v%v", UncertaintyStdDevPrefix, lib.FormatAsCurrency(u.StdDev))
+	case u.Min != u.Max:
+		return fmt.Sprintf("%v%v%v", formatEditableAmount(u.Min), UncertaintyRangeSeparator, formatEditableAmount(u.Max))
+	}
+
+	return ""
+}
+
+// parseUncertainty parses an amount schedule entry that was formatted with
+// formatUncertainty. It returns false if the entry is not an uncertainty.
+func parseUncertainty(entry string) (Uncertainty, bool, error) {
+	if s, ok := strings.CutPrefix(entry, UncertaintyStdDevPrefix); ok {
+		sd := int(lib.ParseDollarAmount(strings.TrimSpace(s), true))
+		if sd <= 0 {
+			return Uncertainty{}, true, fmt.Errorf("%v: %v", FP.T["UncertaintyInvalidStdDev"], entry)
+		}
+
+		return Uncertainty{StdDev: sd}, true, nil
+	}
+
+	lo, hi, ok := strings.Cut(entry, UncertaintyRangeSeparator)
+	if !ok {
+		return Uncertainty{}, false, nil
+	}
+
+	u := Uncertainty{
+		Min: int(lib.ParseDollarAmount(strings.TrimSpace(lo), false)),
+		Max: int(lib.ParseDollarAmount(strings.TrimSpace(hi), false)),
+	}
+
+	if u.Min == u.Max {
+		return Uncertainty{}, true, fmt.Errorf("%v: %v", FP.T["UncertaintyInvalidRange"], entry)
+	}
+
+	return u, true, nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// getTestUncertainTX returns an active monthly transaction that starts at
+// start, never ends, and varies by the given uncertainty.
+func getTestUncertainTX(start time.Time, amount int, uncertainty Uncertainty) TX {
+	tx := getNewTX(start)
+	tx.Amount = amount
+	tx.Uncertainty = uncertainty
+	clearTXEnds(&tx)
+
+	return tx
+}
+
+func TestRunMonteCarlo(t *testing.T) {
+	start := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, DaysPerYear-1)
+	results := getTestResults(start, DaysPerYear, 100000)
+
+	tests := []struct {
+		name         string
+		transactions []TX
+	}{
+		{
+			name:         "range",
+			transactions: []TX{getTestUncertainTX(start, -10000, Uncertainty{Min: -8000, Max: -15000})},
+		},
+		{
+			name:         "standard deviation",
+			transactions: []TX{getTestUncertainTX(start, -10000, Uncertainty{StdDev: 2500})},
+		},
+		{
+			name: "range and standard deviation",
+			transactions: []TX{
+				getTestUncertainTX(start, -10000, Uncertainty{Min: -8000, Max: -15000}),
+				getTestUncertainTX(start.AddDate(0, 0, 14), -20000, Uncertainty{StdDev: 5000}),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Profile{Simulations: 500, SimulationSeed: 42}
+
+			first, err := runMonteCarlo(&p, results, tt.transactions, start, end, HolidayCalendar{})
+			if err != nil {
+				t.Fatalf("failed to run: %v", err)
+			}
+
+			if len(first) != len(results) {
+				t.Fatalf("got %v days, want %v", len(first), len(results))
+			}
+
+			second, err := runMonteCarlo(&p, results, tt.transactions, start, end, HolidayCalendar{})
+			if err != nil {
+				t.Fatalf("failed to run again: %v", err)
+			}
+
+			if !reflect.DeepEqual(first, second) {
+				t.Errorf("got different projections from the same seed")
+			}
+
+			last := first[len(first)-1]
+			if last.P10 > last.P50 || last.P50 > last.P90 || last.P10 == last.P90 {
+				t.Errorf("got percentiles %v, %v, %v, want them to be spread out in order", last.P10, last.P50, last.P90)
+			}
+
+			p.SimulationSeed++
+
+			other, err := runMonteCarlo(&p, results, tt.transactions, start, end, HolidayCalendar{})
+			if err != nil {
+				t.Fatalf("failed to run with another seed: %v", err)
+			}
+
+			if reflect.DeepEqual(first, other) {
+				t.Errorf("got the same projection from a different seed")
+			}
+		})
+	}
+}
@@ -71,6 +71,14 @@ func getProfilesPage() *tview.Flex {
 	FP.TransactionsTable.SetBorder(true)
 	FP.TransactionsInputField.SetBorder(true)
 
+	FP.GoalsTable = tview.NewTable().SetFixed(1, 0)
+	FP.GoalsTable.SetBorder(true)
+	FP.GoalsTable.SetTitle(FP.T["GoalsTableTitle"])
+	FP.GoalsTable.SetBorders(false).
+		SetSelectable(true, true).
+		SetSeparator(' ')
+	FP.GoalsTable.SetSelectedFunc(goalsTableSelectedFunc)
+
 	FP.TransactionsInputField.SetFieldBackgroundColor(tcell.ColorBlack)
 	FP.TransactionsInputField.SetLabel(fmt.Sprintf(
 		"%v%v%v",
@@ -88,6 +96,7 @@ func getProfilesPage() *tview.Flex {
 
 	transactionsPage := tview.NewFlex().SetDirection(tview.FlexRow).
 		AddItem(FP.TransactionsTable, 0, 1, false).
+		AddItem(FP.GoalsTable, GoalsTableHeight, 0, false).
 		AddItem(FP.TransactionsInputField, 3, 0, false)
 
 	return tview.NewFlex().SetDirection(tview.FlexColumn).
@@ -98,38 +107,44 @@ func getProfilesPage() *tview.Flex {
 // sets sensible default values for the currently selected profile, if they are
 // not defined. If there is no FP.SelectedProfile, this will do nothing.
 func setSelectedProfileDefaults() {
-	if FP.SelectedProfile == nil {
+	setProfileDefaults(FP.SelectedProfile)
+}
+
+// sets sensible default values for the provided profile's results parameters,
+// if they are not defined. If p is nil, this will do nothing.
+func setProfileDefaults(p *Profile) {
+	if p == nil {
 		return
 	}
 
 	now := time.Now()
 	yr := now.Add(time.Hour * 24 * 365)
 
-	if FP.SelectedProfile.StartYear == "" {
-		FP.SelectedProfile.StartYear = strconv.Itoa(now.Year())
+	if p.StartYear == "" {
+		p.StartYear = strconv.Itoa(now.Year())
 	}
 
-	if FP.SelectedProfile.StartMonth == "" {
-		FP.SelectedProfile.StartMonth = strconv.Itoa(int(now.Month()))
+	if p.StartMonth == "" {
+		p.StartMonth = strconv.Itoa(int(now.Month()))
 	}
 
-	if FP.SelectedProfile.StartDay == "" {
-		FP.SelectedProfile.StartDay = strconv.Itoa(now.Day())
+	if p.StartDay == "" {
+		p.StartDay = strconv.Itoa(now.Day())
 	}
 
-	if FP.SelectedProfile.EndYear == "" {
-		FP.SelectedProfile.EndYear = strconv.Itoa(yr.Year())
+	if p.EndYear == "" {
+		p.EndYear = strconv.Itoa(yr.Year())
 	}
 
-	if FP.SelectedProfile.EndMonth == "" {
-		FP.SelectedProfile.EndMonth = strconv.Itoa(int(yr.Month()))
+	if p.EndMonth == "" {
+		p.EndMonth = strconv.Itoa(int(yr.Month()))
 	}
 
-	if FP.SelectedProfile.EndDay == "" {
-		FP.SelectedProfile.EndDay = strconv.Itoa(yr.Day())
+	if p.EndDay == "" {
+		p.EndDay = strconv.Itoa(yr.Day())
 	}
 
-	if FP.SelectedProfile.StartingBalance == "" {
-		FP.SelectedProfile.StartingBalance = lib.FormatAsCurrency(50000)
+	if p.StartingBalance == "" {
+		p.StartingBalance = lib.FormatAsCurrency(50000)
 	}
 }
@@ -1,6 +1,13 @@
 package main
 
-import "github.com/gdamore/tcell/v2"
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
 
 // This file mainly contains functions for the hidden prompt page in the
 // application.
@@ -18,6 +25,13 @@ func promptExit() {
 		return
 	}
 
+	unsaved := getUnsavedProfiles()
+	if len(unsaved) > 0 {
+		promptExitUnsaved(unsaved)
+
+		return
+	}
+
 	FP.PromptBox.ClearButtons().AddButtons(
 		[]string{
 			FP.T["PromptExitButtonExit"],
@@ -46,6 +60,55 @@ func promptExit() {
 	FP.App.SetFocus(FP.PromptBox)
 }
 
+// promptExitUnsaved is shown instead of the regular exit prompt when any
+// profiles have unsaved changes. It lists the profiles and lets the user save
+// before exiting, exit without saving, or cancel.
+func promptExitUnsaved(unsaved []string) {
+	FP.PromptBox.ClearButtons().AddButtons(
+		[]string{
+			FP.T["PromptExitButtonSaveAndExit"],
+			FP.T["PromptExitButtonDiscard"],
+			FP.T["PromptExitButtonCancel"],
+		},
+	).SetText(fmt.Sprintf("%v\n\n%v",
+		FP.T["PromptExitUnsavedText"],
+		strings.Join(unsaved, "\n"),
+	)).SetDoneFunc(
+		func(buttonIndex int, _ /* buttonLabel */ string) {
+			switch buttonIndex {
+			case 0:
+				if guardExternalConfigChange() {
+					return
+				}
+
+				err := saveConfig(FP.FlagConfigFile, &FP.Config)
+				if err != nil {
+					FP.Pages.SwitchToPage(FP.PrevPage)
+					FP.ProfileStatusText.SetText(fmt.Sprintf("%v%v%v",
+						FP.Colors["ProfileStatusTextError"],
+						tview.Escape(err.Error()),
+						Reset,
+					))
+
+					return
+				}
+
+				markSaved()
+				FP.App.Stop()
+			case 1:
+				FP.App.Stop()
+			default:
+				FP.Pages.SwitchToPage(FP.PrevPage)
+			}
+		},
+	).SetBackgroundColor(tcell.ColorGoldenrod).
+		SetTextColor(tcell.ColorBlack)
+
+	FP.Pages.SwitchToPage(PagePrompt)
+	FP.PromptBox.SetFocus(2)
+	FP.App.SetFocus(FP.PromptBox)
+}
+
 // promptKBMode switches to the prompt page and shows a modal that informs the
 // user that they are in keyboard echo mode. If KB echo mode is not enabled,
 // this gracefully returns immediately and does nothing.
@@ -91,3 +154,53 @@ func promptKBMode(t map[string]string) {
 	FP.PromptBox.SetFocus(2)
 	FP.App.SetFocus(FP.PromptBox)
 }
+
+// promptRRulePreview shows the next occurrences of an rrule that was entered
+// for the i'th transaction, and lets the user accept it, go back to editing
+// it, or cancel.
+func promptRRulePreview(i int, rule string, occurrences []time.Time) {
+	lines := make([]string, len(occurrences))
+	for j := range occurrences {
+		lines[j] = occurrences[j].Format(RRulePreviewDateFormat)
+	}
+
+	if len(lines) == 0 {
+		lines = append(lines, FP.T["PromptRRulePreviewNoOccurrences"])
+	}
+
+	done := func() {
+		FP.Pages.SwitchToPage(PageProfiles)
+		FP.App.SetFocus(FP.TransactionsTable)
+	}
+
+	FP.PromptBox.ClearButtons().AddButtons(
+		[]string{
+			FP.T["PromptRRulePreviewButtonAccept"],
+			FP.T["PromptRRulePreviewButtonEdit"],
+			FP.T["PromptRRulePreviewButtonCancel"],
+		},
+	).SetText(fmt.Sprintf("%v\n%v\n\n%v",
+		FP.T["PromptRRulePreviewText"],
+		tview.Escape(rule),
+		strings.Join(lines, "\n"),
+	)).SetDoneFunc(
+		func(buttonIndex int, _ /* buttonLabel */ string) {
+			switch buttonIndex {
+			case 0:
+				done()
+				txSetRRule(i, rule)
+				modified()
+			case 1:
+				done()
+				txEditRRule(i, rule)
+			default:
+				done()
+			}
+		},
+	).SetBackgroundColor(tcell.ColorDimGray).
+		SetTextColor(tcell.ColorWhite)
+
+	FP.Pages.SwitchToPage(PagePrompt)
+	FP.PromptBox.SetFocus(0)
+	FP.App.SetFocus(FP.PromptBox)
+}
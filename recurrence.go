@@ -0,0 +1,408 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	lib "github.com/charles-m-knox/finance-planner-lib"
+
+	"github.com/teambition/rrule-go"
+)
+
+// This file contains the logic for recurrence patterns that the library does
+// not support natively. Before results are generated, transactions that use
+// them are converted into an explicit list of dates in the form of an rrule
+// set, which the library then processes like any other rrule.
+
+// The layout used by rrule sets for UTC date-times.
+const RRuleDateTimeFormat = "20060102T150405Z"
+
+// The latest date that is checked when looking for a transaction's final
+// occurrence.
+var MaxFinalOccurrenceDate = time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+// The number of upcoming occurrences shown when previewing an rrule.
+const RRulePreviewCount = 10
+
+// The layout used for dates when previewing an rrule.
+const RRulePreviewDateFormat = "2006-01-02 Mon"
+
+// hasWeekday returns true if the transaction is allowed to occur on the given
+// day of the week. When no weekdays are checked, every day is allowed, which
+// matches how the library treats daily recurrence.
+func hasWeekday(tx *TX, wd time.Weekday) bool {
+	checked := false
+
+	for _, v := range tx.Weekdays {
+		if v {
+			checked = true
+
+			break
+		}
+	}
+
+	// the library's weekdays start on monday=0, while go's start on sunday=0
+	return !checked || tx.Weekdays[(int(wd)+6)%7]
+}
+
+// hasTXStartDate returns true if the transaction has its own start date.
+func hasTXStartDate(tx *TX) bool {
+	return tx.StartsYear != 0 || tx.StartsMonth != 0 || tx.StartsDay != 0
+}
+
+// getTXDateRange returns the first and last dates that the transaction may
+// occur on, bounded by end. Unset dates default to start and end, just like
+// the library does with the results' start and end dates.
+func getTXDateRange(tx *TX, start, end time.Time) (time.Time, time.Time) {
+	txStart := start
+	if hasTXStartDate(tx) {
+		txStart = time.Date(tx.StartsYear, time.Month(tx.StartsMonth), tx.StartsDay, 0, 0, 0, 0, time.UTC)
+	}
+
+	txEnd := end
+	if tx.EndsYear != 0 || tx.EndsMonth != 0 || tx.EndsDay != 0 {
+		txEnd = time.Date(tx.EndsYear, time.Month(tx.EndsMonth), tx.EndsDay, 0, 0, 0, 0, time.UTC)
+	}
+
+	if txEnd.After(end) {
+		txEnd = end
+	}
+
+	return txStart, txEnd
+}
+
+// hasBusinessWeekday returns true if any of the transaction's checked
+// weekdays is a business day, from monday to friday.
+func hasBusinessWeekday(tx *TX) bool {
+	for wd := time.Monday; wd <= time.Friday; wd++ {
+		if hasWeekday(tx, wd) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getBusinessDayOccurrences returns every date between from and to on which
+// a BUSINESSDAYS transaction occurs. The transaction occurs on every
+// <interval>th business day, counting from its own start date (or start, if
+// it is unset), and only on its checked weekdays, until it has occurred the
+// maximum number of times.
+func getBusinessDayOccurrences(tx *TX, start, from, to time.Time, holidays HolidayCalendar) []time.Time {
+	interval := tx.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	txStart, txEnd := getTXDateRange(tx, start, to)
+	occurrences := []time.Time{}
+
+	// a transaction that is only checked on weekends never occurs, so don't
+	// search for its occurrences until the end, which may be as late as
+	// MaxFinalOccurrenceDate
+	if !hasBusinessWeekday(tx) {
+		return occurrences
+	}
+	n := 0 // business days so far
+	k := 0 // occurrences so far
+
+	for d := txStart; !d.After(txEnd); d = d.AddDate(0, 0, 1) {
+		if !isBusinessDay(d, holidays) || !hasWeekday(tx, d.Weekday()) {
+			continue
+		}
+
+		n++
+
+		if (n-1)%interval != 0 {
+			continue
+		}
+
+		k++
+
+		if tx.Occurrences > 0 && k > tx.Occurrences {
+			break
+		}
+
+		if d.Before(from) {
+			continue
+		}
+
+		occurrences = append(occurrences, d)
+	}
+
+	return occurrences
+}
+
+// getTXOccurrences returns every date between from and to on which the
+// transaction occurs, before any roll convention is applied. Unset start
+// dates default to start, except for transactions with a limited number of
+// occurrences, which need a start date to count them from, so that they
+// don't depend on the dates of the results. For frequencies that the library
+// supports, the recurrence is built the same way that the library builds it.
+func getTXOccurrences(tx *TX, start, from, to time.Time, holidays HolidayCalendar) ([]time.Time, error) {
+	if strings.TrimSpace(tx.RRule) != "" {
+		set, err := getTXRRule(tx, start)
+		if err != nil {
+			return nil, err
+		}
+
+		return set.Between(from, to, true), nil
+	}
+
+	if tx.Occurrences > 0 && !hasTXStartDate(tx) {
+		return nil, fmt.Errorf("%v", FP.T["OccurrencesNoStartDate"])
+	}
+
+	if tx.Frequency == BUSINESSDAYS {
+		return getBusinessDayOccurrences(tx, start, from, to, holidays), nil
+	}
+
+	txStart, txEnd := getTXDateRange(tx, start, to)
+	opt := rrule.ROption{Dtstart: txStart, Until: txEnd, Interval: tx.Interval, Count: tx.Occurrences}
+
+	switch tx.Frequency {
+	case YEARLY:
+		opt.Freq = rrule.YEARLY
+	case MONTHLY:
+		opt.Freq = rrule.MONTHLY
+	default:
+		opt.Freq = rrule.DAILY
+
+		// the library's weekdays start on monday=0, just like rrule's
+		weekdays := []rrule.Weekday{rrule.MO, rrule.TU, rrule.WE, rrule.TH, rrule.FR, rrule.SA, rrule.SU}
+		for i := range weekdays {
+			if tx.Weekdays[i] {
+				opt.Byweekday = append(opt.Byweekday, weekdays[i])
+			}
+		}
+	}
+
+	r, err := rrule.NewRRule(opt)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", FP.T["RRuleInvalid"], err)
+	}
+
+	return r.Between(from, to, true), nil
+}
+
+// getRRuleSet formats a list of dates as an rrule set that the library can
+// process. The dates must be at UTC midnight.
+func getRRuleSet(dates []time.Time) string {
+	d := make([]string, len(dates))
+	for i := range dates {
+		d[i] = dates[i].Format(RRuleDateTimeFormat)
+	}
+
+	return fmt.Sprintf("DTSTART:%v\nRDATE:%v", d[0], strings.Join(d, ","))
+}
+
+// getTXRRule parses the transaction's rrule. The rrule can be a full rrule
+// set, starting with a DTSTART line, or just the rule itself, such as
+// FREQ=MONTHLY;BYDAY=TU;BYSETPOS=2. When only the rule is given, it starts on
+// the transaction's start date (or start, if the start date is unset) and
+// ends on the transaction's end date, unless the rule says otherwise. Either
+// way, the transaction's occurrences are used as the rule's COUNT, unless the
+// rule already has one. A rule with a COUNT needs a start date to count from,
+// either its own or the transaction's.
+func getTXRRule(tx *TX, start time.Time) (*rrule.Set, error) {
+	s := strings.TrimSpace(tx.RRule)
+	if strings.HasPrefix(s, "DTSTART") {
+		set, err := rrule.StrToRRuleSet(s)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %w", FP.T["RRuleInvalid"], err)
+		}
+
+		r := set.GetRRule()
+		if r == nil || r.OrigOptions.Count != 0 || tx.Occurrences < 1 {
+			return set, nil
+		}
+
+		opt := r.OrigOptions
+		opt.Count = tx.Occurrences
+
+		r, err = rrule.NewRRule(opt)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %w", FP.T["RRuleInvalid"], err)
+		}
+
+		set.RRule(r)
+
+		return set, nil
+	}
+
+	opt, err := rrule.StrToROption(s)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", FP.T["RRuleInvalid"], err)
+	}
+
+	if opt.Count == 0 {
+		opt.Count = tx.Occurrences
+	}
+
+	if opt.Dtstart.IsZero() {
+		if opt.Count != 0 && !hasTXStartDate(tx) {
+			return nil, fmt.Errorf("%v", FP.T["OccurrencesNoStartDate"])
+		}
+
+		opt.Dtstart = start
+		if hasTXStartDate(tx) {
+			opt.Dtstart = time.Date(tx.StartsYear, time.Month(tx.StartsMonth), tx.StartsDay, 0, 0, 0, 0, time.UTC)
+		}
+	}
+
+	if opt.Until.IsZero() && opt.Count == 0 && (tx.EndsYear != 0 || tx.EndsMonth != 0 || tx.EndsDay != 0) {
+		opt.Until = time.Date(tx.EndsYear, time.Month(tx.EndsMonth), tx.EndsDay, 0, 0, 0, 0, time.UTC)
+	}
+
+	r, err := rrule.NewRRule(*opt)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", FP.T["RRuleInvalid"], err)
+	}
+
+	set := &rrule.Set{}
+	set.RRule(r)
+
+	return set, nil
+}
+
+// getRRuleOccurrences returns up to n occurrences of the rrule set, starting
+// with from (inclusive).
+func getRRuleOccurrences(set *rrule.Set, from time.Time, n int) []time.Time {
+	occurrences := []time.Time{}
+
+	for dt := set.After(from, true); !dt.IsZero() && len(occurrences) < n; dt = set.After(dt, false) {
+		occurrences = append(occurrences, dt)
+	}
+
+	return occurrences
+}
+
+// getFinalOccurrence returns the date of the transaction's last occurrence,
+// including its roll convention, if the transaction is limited to a number of
+// occurrences and has a start date to count them from.
+func getFinalOccurrence(tx *TX, holidays HolidayCalendar) (time.Time, bool) {
+	if tx.Occurrences < 1 {
+		return time.Time{}, false
+	}
+
+	// the number of occurrences is limited and counted from the
+	// transaction's own start date, so this doesn't run forever, and the
+	// start passed here is never used
+	occurrences, err := getTXOccurrences(tx, time.Time{}, time.Time{}, MaxFinalOccurrenceDate, holidays)
+	if err != nil || len(occurrences) == 0 {
+		return time.Time{}, false
+	}
+
+	return rollDate(occurrences[len(occurrences)-1], getRoll(tx.Roll), holidays), true
+}
+
+// appendOccurrences appends the transaction to txs, as an rrule set of the
+// given dates, which must be sorted. Since an rrule set can't contain the same
+// date twice, a copy of the transaction is appended for each extra occurrence
+// on the same date, which can happen when occurrences are rolled.
+func appendOccurrences(txs []lib.TX, tx lib.TX, dates []time.Time) []lib.TX {
+	for len(dates) > 0 {
+		unique := []time.Time{}
+		duplicates := []time.Time{}
+
+		for i := range dates {
+			if i > 0 && dates[i].Equal(dates[i-1]) {
+				duplicates = append(duplicates, dates[i])
+
+				continue
+			}
+
+			unique = append(unique, dates[i])
+		}
+
+		t := tx
+		t.RRule = getRRuleSet(unique)
+		txs = append(txs, t)
+		dates = duplicates
+	}
+
+	return txs
+}
+
+// prepareTransactions converts the transactions into the library's
+// transactions. Every transaction with a recurrence pattern, roll convention
+// or amount schedule that the library can't handle is converted into rrule
+// sets of its occurrences between start and end, one for each amount.
+// Transactions with their own rrule are converted into a full rrule set that
+// starts on the transaction's start date.
+func prepareTransactions(txs []TX, start, end time.Time, holidays HolidayCalendar) ([]lib.TX, error) {
+	result := make([]lib.TX, 0, len(txs))
+
+	for i := range txs {
+		tx := &(txs[i])
+		roll := getRoll(tx.Roll) != RollNone
+		hasRRule := strings.TrimSpace(tx.RRule) != ""
+		hasSchedule := hasAmountSchedule(tx)
+
+		switch {
+		case !tx.Active, !roll && !hasSchedule && !hasRRule && tx.Frequency != BUSINESSDAYS && tx.Occurrences == 0:
+			// the library can handle these as they are
+			result = append(result, tx.TX)
+
+			continue
+		case !roll && !hasSchedule && hasRRule:
+			set, err := getTXRRule(tx, start)
+			if err != nil {
+				return result, fmt.Errorf("%v: %w", tx.Name, err)
+			}
+
+			t := tx.TX
+			t.RRule = set.String()
+			result = append(result, t)
+
+			continue
+		}
+
+		// occurrences just outside of the results may be rolled into them
+		from, to := start, end
+		if roll {
+			from = start.AddDate(0, 0, -RollWindow)
+			to = end.AddDate(0, 0, RollWindow)
+		}
+
+		occurrences, err := getTXOccurrences(tx, start, from, to, holidays)
+		if err != nil {
+			return result, fmt.Errorf("%v: %w", tx.Name, err)
+		}
+
+		steps, err := getAmountSteps(tx, start)
+		if err != nil {
+			return result, fmt.Errorf("%v: %w", tx.Name, err)
+		}
+
+		// occurrences are grouped by their amount, which is determined by
+		// their date before rolling. Rolling never changes the order of the
+		// occurrences, so each group remains sorted.
+		amounts := []int{}
+		dates := make(map[int][]time.Time)
+
+		for _, d := range occurrences {
+			amount := getAmountOn(steps, tx.Escalation, d)
+
+			d = rollDate(d, tx.Roll, holidays)
+			if d.Before(start) || d.After(end) {
+				continue
+			}
+
+			if _, ok := dates[amount]; !ok {
+				amounts = append(amounts, amount)
+			}
+
+			dates[amount] = append(dates[amount], d)
+		}
+
+		for _, amount := range amounts {
+			t := tx.TX
+			t.Amount = amount
+			result = appendOccurrences(result, t, dates[amount])
+		}
+	}
+
+	return result, nil
+}
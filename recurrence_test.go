@@ -0,0 +1,244 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/teambition/rrule-go"
+)
+
+func TestPrepareTransactions(t *testing.T) {
+	date := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	// getTX returns an active transaction of $-10.00 with the given
+	// frequency that starts on the given date and never ends
+	getTX := func(frequency string, start time.Time) TX {
+		tx := getNewTX(start)
+		tx.Amount = -1000
+		tx.Frequency = frequency
+		clearTXEnds(&tx)
+
+		return tx
+	}
+
+	start, end := date(2025, time.January, 1), date(2025, time.June, 30)
+
+	tests := []struct {
+		name string
+		tx   func() TX
+		// each occurrence as "YYYY-MM-DD amount", sorted by date; nil if the
+		// transaction should be passed to the library as it is
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "passed through",
+			tx:   func() TX { return getTX(MONTHLY, date(2025, time.January, 15)) },
+		},
+		{
+			name: "occurrences",
+			tx: func() TX {
+				tx := getTX(MONTHLY, date(2025, time.January, 15))
+				tx.Occurrences = 3
+
+				return tx
+			},
+			want: []string{"2025-01-15 -1000", "2025-02-15 -1000", "2025-03-15 -1000"},
+		},
+		{
+			name: "occurrences counted from a start date before the results",
+			tx: func() TX {
+				tx := getTX(MONTHLY, date(2024, time.November, 15))
+				tx.Occurrences = 4
+
+				return tx
+			},
+			want: []string{"2025-01-15 -1000", "2025-02-15 -1000"},
+		},
+		{
+			name: "occurrences without a start date",
+			tx: func() TX {
+				tx := getTX(MONTHLY, date(2025, time.January, 15))
+				tx.Occurrences = 3
+				tx.StartsYear, tx.StartsMonth, tx.StartsDay = 0, 0, 0
+
+				return tx
+			},
+			wantErr: true,
+		},
+		{
+			name: "rrule count without a start date",
+			tx: func() TX {
+				tx := getTX(MONTHLY, date(2025, time.January, 15))
+				tx.RRule = "FREQ=MONTHLY;COUNT=2"
+				tx.StartsYear, tx.StartsMonth, tx.StartsDay = 0, 0, 0
+
+				return tx
+			},
+			wantErr: true,
+		},
+		{
+			name: "rolled to the previous business day",
+			tx: func() TX {
+				// 2025-03-01 and 2025-06-01 are on weekends
+				tx := getTX(MONTHLY, date(2025, time.March, 1))
+				tx.Roll = RollPrevious
+				tx.Occurrences = 4
+
+				return tx
+			},
+			want: []string{"2025-02-28 -1000", "2025-04-01 -1000", "2025-05-01 -1000", "2025-05-30 -1000"},
+		},
+		{
+			name: "rolled onto the same day",
+			tx: func() TX {
+				// saturday 2025-01-04 and sunday 2025-01-05 both roll to
+				// friday 2025-01-03
+				tx := getTX(DAILY, date(2025, time.January, 3))
+				tx.Roll = RollPrevious
+				tx.Occurrences = 3
+
+				return tx
+			},
+			want: []string{"2025-01-03 -1000", "2025-01-03 -1000", "2025-01-03 -1000"},
+		},
+		{
+			name: "amount changes",
+			tx: func() TX {
+				tx := getTX(MONTHLY, date(2025, time.January, 15))
+				tx.Occurrences = 3
+				tx.AmountChanges = []AmountChange{{Date: "2025-02-01", Amount: -2000}}
+
+				return tx
+			},
+			want: []string{"2025-01-15 -1000", "2025-02-15 -2000", "2025-03-15 -2000"},
+		},
+		{
+			name: "business days",
+			tx: func() TX {
+				tx := getTX(BUSINESSDAYS, date(2025, time.January, 2))
+				tx.Interval = 2
+				tx.Occurrences = 3
+
+				return tx
+			},
+			want: []string{"2025-01-02 -1000", "2025-01-06 -1000", "2025-01-08 -1000"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := tt.tx()
+
+			txs, err := prepareTransactions([]TX{tx}, start, end, HolidayCalendar{})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got no error, want one")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("failed to prepare transactions: %v", err)
+			}
+
+			if tt.want == nil {
+				if len(txs) != 1 || txs[0].RRule != tx.RRule {
+					t.Fatalf("got %v, want the transaction as it is", txs)
+				}
+
+				return
+			}
+
+			got := []string{}
+
+			for i := range txs {
+				set, err := rrule.StrToRRuleSet(txs[i].RRule)
+				if err != nil {
+					t.Fatalf("failed to parse %v: %v", txs[i].RRule, err)
+				}
+
+				for _, d := range set.All() {
+					got = append(got, fmt.Sprintf("%v %v", d.Format(time.DateOnly), txs[i].Amount))
+				}
+			}
+
+			slices.Sort(got)
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetFinalOccurrence(t *testing.T) {
+	// getTX returns an active transaction with the given frequency that
+	// starts on 2025-01-15 and occurs 3 times
+	getTX := func(frequency string) TX {
+		tx := getNewTX(time.Date(2025, time.January, 15, 0, 0, 0, 0, time.UTC))
+		tx.Frequency = frequency
+		tx.Occurrences = 3
+		clearTXEnds(&tx)
+
+		return tx
+	}
+
+	tests := []struct {
+		name string
+		tx   func() TX
+		// the final occurrence as "YYYY-MM-DD", or empty if there is none
+		want string
+	}{
+		{
+			name: "monthly",
+			tx:   func() TX { return getTX(MONTHLY) },
+			want: "2025-03-15",
+		},
+		{
+			name: "business days",
+			tx:   func() TX { return getTX(BUSINESSDAYS) },
+			want: "2025-01-17",
+		},
+		{
+			name: "business days on weekends only",
+			tx: func() TX {
+				tx := getTX(BUSINESSDAYS)
+				for i := range tx.Weekdays {
+					tx.Weekdays[i] = i == rrule.SA.Day() || i == rrule.SU.Day()
+				}
+
+				return tx
+			},
+		},
+		{
+			name: "unlimited",
+			tx: func() TX {
+				tx := getTX(MONTHLY)
+				tx.Occurrences = 0
+
+				return tx
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := tt.tx()
+
+			got := ""
+			if final, ok := getFinalOccurrence(&tx, HolidayCalendar{}); ok {
+				got = final.Format(time.DateOnly)
+			}
+
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
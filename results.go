@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -48,6 +49,25 @@ func resultsFormInputFieldDayValidator(textToCheck string, _ rune) bool {
 	return true
 }
 
+// When changing the number of simulations in the results form, this function
+// is executed and will reject anything but a number from 0 to MaxSimulations.
+func resultsFormInputFieldSimulationsValidator(textToCheck string, _ rune) bool {
+	i, err := strconv.ParseInt(textToCheck, 10, 64)
+	if err != nil || i < 0 || i > MaxSimulations {
+		return false
+	}
+
+	return true
+}
+
+// When changing the simulation seed in the results form, this function is
+// executed and will reject anything but a whole number.
+func resultsFormInputFieldSeedValidator(textToCheck string, _ rune) bool {
+	_, err := strconv.ParseInt(textToCheck, 10, 64)
+
+	return err == nil
+}
+
 func resultsFormInputFieldStartYearChanged(text string) {
 	FP.SelectedProfile.StartYear = text
 }
@@ -68,6 +88,15 @@ func resultsFormSubmit5Yr() {
 	getResultsTable()
 }
 
+// When changing an interest rate field in the results form, this function is
+// executed and will reject changes that do not properly parse into a
+// percentage.
+func resultsFormInputFieldInterestRateValidator(textToCheck string, _ rune) bool {
+	_, err := parseInterestRate(textToCheck)
+
+	return err == nil
+}
+
 // Completely rebuilds the results form, safe to run repeatedly.
 func updateResultsForm() {
 	FP.ResultsForm.Clear(true)
@@ -79,6 +108,8 @@ func updateResultsForm() {
 
 	setSelectedProfileDefaults()
 
+	compoundingOptions := []string{DAILY, MONTHLY, YEARLY}
+
 	FP.ResultsForm.
 		AddInputField(getResultsFormLabel(FP.T["ResultsFormStartYearLabel"]),
 			FP.SelectedProfile.StartYear,
@@ -110,6 +141,60 @@ func updateResultsForm() {
 			func(text string) {
 				FP.SelectedProfile.StartingBalance = lib.FormatAsCurrency(int(lib.ParseDollarAmount(text, true)))
 			}).
+		AddInputField(getResultsFormLabel(FP.T["ResultsFormMinimumBalanceLabel"]),
+			FP.SelectedProfile.MinimumBalance,
+			0, nil,
+			func(text string) {
+				// an empty minimum balance means that there is none
+				FP.SelectedProfile.MinimumBalance = ""
+				if strings.TrimSpace(text) != "" {
+					FP.SelectedProfile.MinimumBalance = lib.FormatAsCurrency(int(lib.ParseDollarAmount(text, true)))
+				}
+			}).
+		AddInputField(getResultsFormLabel(FP.T["ResultsFormAccountsLabel"]),
+			formatAccounts(FP.SelectedProfile.Accounts),
+			0, nil,
+			func(text string) {
+				// duplicate names are ignored until they are fixed
+				accounts, err := parseAccounts(text)
+				if err == nil {
+					FP.SelectedProfile.Accounts = accounts
+				}
+			}).
+		AddInputField(getResultsFormLabel(FP.T["ResultsFormAPYLabel"]),
+			formatInterestRate(FP.SelectedProfile.APY),
+			0, resultsFormInputFieldInterestRateValidator,
+			func(text string) {
+				FP.SelectedProfile.APY, _ = parseInterestRate(text)
+			}).
+		AddInputField(getResultsFormLabel(FP.T["ResultsFormAPRLabel"]),
+			formatInterestRate(FP.SelectedProfile.APR),
+			0, resultsFormInputFieldInterestRateValidator,
+			func(text string) {
+				FP.SelectedProfile.APR, _ = parseInterestRate(text)
+			}).
+		AddDropDown(getResultsFormLabel(FP.T["ResultsFormCompoundingLabel"]),
+			compoundingOptions,
+			slices.Index(compoundingOptions, getCompounding(FP.SelectedProfile.InterestCompounding)),
+			func(option string, _ int) {
+				// the initial option is also selected when the form is built,
+				// which should not change the profile
+				if option != getCompounding(FP.SelectedProfile.InterestCompounding) {
+					FP.SelectedProfile.InterestCompounding = option
+				}
+			}).
+		AddInputField(getResultsFormLabel(FP.T["ResultsFormSimulationsLabel"]),
+			strconv.Itoa(FP.SelectedProfile.Simulations),
+			0, resultsFormInputFieldSimulationsValidator,
+			func(text string) {
+				FP.SelectedProfile.Simulations, _ = strconv.Atoi(text)
+			}).
+		AddInputField(getResultsFormLabel(FP.T["ResultsFormSimulationSeedLabel"]),
+			strconv.FormatInt(FP.SelectedProfile.SimulationSeed, 10),
+			0, resultsFormInputFieldSeedValidator,
+			func(text string) {
+				FP.SelectedProfile.SimulationSeed, _ = strconv.ParseInt(text, 10, 64)
+			}).
 		AddButton(FP.T["ResultsFormSubmitButtonLabel"], getResultsTable).
 		AddButton(FP.T["ResultsForm1yearButtonLabel"], resultsFormSubmit1Yr).
 		AddButton(FP.T["ResultsForm5yearsButtonLabel"], resultsFormSubmit5Yr).
@@ -136,9 +221,14 @@ func getResultsPage() *tview.Flex {
 	FP.ResultsDescription = tview.NewTextView().SetDynamicColors(true)
 	FP.ResultsDescription.SetBorder(true)
 
+	FP.ResultsInputField = tview.NewInputField()
+	FP.ResultsInputField.SetBorder(true)
+	deactivateResultsInputField()
+
 	resultsRightSide := tview.NewFlex().SetDirection(tview.FlexRow).
 		AddItem(FP.ResultsTable, 0, 2, true).
-		AddItem(FP.ResultsDescription, 0, 1, false)
+		AddItem(FP.ResultsDescription, 0, 1, false).
+		AddItem(FP.ResultsInputField, 3, 0, false)
 
 	return tview.NewFlex().SetDirection(tview.FlexColumn).
 		AddItem(FP.ResultsForm, 0, 1, true).
@@ -184,6 +274,29 @@ func getResultsStats() {
 		}
 
 		stats := lib.GetStats(*(FP.LatestResults))
+		if FP.SelectedProfile != nil && hasInterest(FP.SelectedProfile) {
+			stats = fmt.Sprintf("%v\n%v", stats, getInterestStats(FP.LatestResultsExtra.Interest))
+		}
+
+		if FP.SelectedProfile != nil && len(*(FP.LatestResults)) > 0 {
+			stats = fmt.Sprintf("%v\n%v", stats, getBalanceAlertsStats(
+				FP.SelectedProfile,
+				*(FP.LatestResults),
+				FP.LatestResultsExtra.Alerts,
+			))
+		}
+
+		if FP.SelectedProfile != nil && len(FP.LatestResultsExtra.MonteCarlo) > 0 {
+			stats = fmt.Sprintf("%v\n%v", stats, getMonteCarloStats(FP.SelectedProfile, FP.LatestResultsExtra.MonteCarlo))
+		}
+
+		if FP.SelectedProfile != nil && len(FP.LatestResultsExtra.Goals) > 0 {
+			stats = fmt.Sprintf("%v\n%v", stats, getGoalsStats(
+				FP.SelectedProfile,
+				*(FP.LatestResults),
+				FP.LatestResultsExtra.Goals,
+			))
+		}
 		// if err != nil {
 		// 	FP.ResultsDescription.SetText(fmt.Sprintf(
 		// 		"%v%v: %v%v",
@@ -204,41 +317,105 @@ func getResultsStats() {
 }
 
 // Returns a list, representing the ordered columns to be shown in
-// the results table, alongside their configured colors.
-func getResultsTableHeaders() []TableCell {
-	return []TableCell{
+// the results table, alongside their configured colors. If the profile has
+// accounts, each account's balance comes before the total balance, and if it
+// has loans, the remaining principal of each loan comes after it. If the
+// profile has investments or loans, the net worth comes after the difference
+// from the start, followed by the Monte Carlo projection, if it has
+// simulations.
+func getResultsTableHeaders(extra ResultsExtra) []TableCell {
+	cells := []TableCell{
 		{Text: FP.T["ResultsColumnDate"], Color: FP.Colors["ResultsColumnDate"]},
-		{Text: FP.T["ResultsColumnBalance"], Color: FP.Colors["ResultsColumnBalance"]},
+	}
+
+	for _, name := range extra.Accounts.Names {
+		cells = append(cells, TableCell{Text: getAccountName(name), Color: FP.Colors["ResultsColumnAccountBalance"]})
+	}
+
+	cells = append(cells, TableCell{Text: getResultsBalanceHeader(extra.Accounts), Color: FP.Colors["ResultsColumnBalance"]})
+
+	for _, name := range extra.Loans.Names {
+		cells = append(cells, TableCell{Text: getLoanColumnName(name), Color: FP.Colors["ResultsColumnLoanBalance"]})
+	}
+
+	cells = append(cells, []TableCell{
 		{Text: FP.T["ResultsColumnCumulativeIncome"], Color: FP.Colors["ResultsColumnCumulativeIncome"]},
 		{Text: FP.T["ResultsColumnCumulativeExpenses"], Color: FP.Colors["ResultsColumnCumulativeExpenses"]},
 		{Text: FP.T["ResultsColumnDayExpenses"], Color: FP.Colors["ResultsColumnDayExpenses"]},
 		{Text: FP.T["ResultsColumnDayIncome"], Color: FP.Colors["ResultsColumnDayIncome"]},
 		{Text: FP.T["ResultsColumnDayNet"], Color: FP.Colors["ResultsColumnDayNet"]},
 		{Text: FP.T["ResultsColumnDiffFromStart"], Color: FP.Colors["ResultsColumnDiffFromStart"]},
-		{Text: FP.T["ResultsColumnDayTransactionNames"], Color: FP.Colors["ResultsColumnDayTransactionNames"], Expand: 1},
+	}...)
+
+	if extra.NetWorth != nil {
+		cells = append(cells, TableCell{Text: FP.T["ResultsColumnNetWorth"], Color: FP.Colors["ResultsColumnNetWorth"]})
 	}
+
+	if extra.MonteCarlo != nil {
+		cells = append(cells, []TableCell{
+			{Text: FP.T["ResultsColumnP10"], Color: FP.Colors["ResultsColumnPercentile"]},
+			{Text: FP.T["ResultsColumnP50"], Color: FP.Colors["ResultsColumnPercentile"]},
+			{Text: FP.T["ResultsColumnP90"], Color: FP.Colors["ResultsColumnPercentile"]},
+			{Text: FP.T["ResultsColumnNegativeProbability"], Color: FP.Colors["ResultsColumnNegativeProbability"]},
+		}...)
+	}
+
+	return append(cells, TableCell{
+		Text: FP.T["ResultsColumnDayTransactionNames"], Color: FP.Colors["ResultsColumnDayTransactionNames"], Expand: 1,
+	})
 }
 
 // Returns a list, representing the ordered columns to be shown in
-// the results table, alongside their configured colors.
-func getResultsTableCell(r lib.Result) []TableCell {
-	return []TableCell{
+// the results table, alongside their configured colors. r is the i'th result,
+// and the balances of the profile's accounts, the remaining principal of its
+// loans, its net worth and its Monte Carlo projection on the same day are
+// taken from extra.
+func getResultsTableCell(r lib.Result, extra ResultsExtra, i int) []TableCell {
+	cells := []TableCell{
 		{Text: lib.GetNowDateString(r.Date), Color: FP.Colors["ResultsColumnDate"]},
-		{Text: lib.FormatAsCurrency(r.Balance), Color: FP.Colors["ResultsColumnBalance"]},
+	}
+
+	for _, balance := range extra.Accounts.GetBalances(i) {
+		cells = append(cells, TableCell{Text: lib.FormatAsCurrency(balance), Color: FP.Colors["ResultsColumnAccountBalance"]})
+	}
+
+	cells = append(cells, TableCell{Text: lib.FormatAsCurrency(r.Balance), Color: FP.Colors["ResultsColumnBalance"]})
+
+	for _, loan := range extra.Loans.GetBalances(i) {
+		cells = append(cells, TableCell{Text: lib.FormatAsCurrency(loan), Color: FP.Colors["ResultsColumnLoanBalance"]})
+	}
+
+	cells = append(cells, []TableCell{
 		{Text: lib.FormatAsCurrency(r.CumulativeIncome), Color: FP.Colors["ResultsColumnCumulativeIncome"]},
 		{Text: lib.FormatAsCurrency(r.CumulativeExpenses), Color: FP.Colors["ResultsColumnCumulativeExpenses"]},
 		{Text: lib.FormatAsCurrency(r.DayExpenses), Color: FP.Colors["ResultsColumnDayExpenses"]},
 		{Text: lib.FormatAsCurrency(r.DayIncome), Color: FP.Colors["ResultsColumnDayIncome"]},
 		{Text: lib.FormatAsCurrency(r.DayNet), Color: FP.Colors["ResultsColumnDayNet"]},
 		{Text: lib.FormatAsCurrency(r.DiffFromStart), Color: FP.Colors["ResultsColumnDiffFromStart"]},
-		{Text: r.DayTransactionNames, Color: FP.Colors["ResultsColumnDayTransactionNames"], Expand: 1},
+	}...)
+
+	if netWorth, ok := extra.GetNetWorth(i); ok {
+		cells = append(cells, TableCell{Text: lib.FormatAsCurrency(netWorth), Color: FP.Colors["ResultsColumnNetWorth"]})
 	}
+
+	if day, ok := extra.GetMonteCarlo(i); ok {
+		cells = append(cells, []TableCell{
+			{Text: lib.FormatAsCurrency(day.P10), Color: FP.Colors["ResultsColumnPercentile"]},
+			{Text: lib.FormatAsCurrency(day.P50), Color: FP.Colors["ResultsColumnPercentile"]},
+			{Text: lib.FormatAsCurrency(day.P90), Color: FP.Colors["ResultsColumnPercentile"]},
+			{Text: formatProbability(day.NegativeProbability), Color: FP.Colors["ResultsColumnNegativeProbability"]},
+		}...)
+	}
+
+	return append(cells, TableCell{
+		Text: r.DayTransactionNames, Color: FP.Colors["ResultsColumnDayTransactionNames"], Expand: 1,
+	})
 }
 
 // Constructs and sets the columns for the first row in the results table.
 // Unsafe to run repeatedly and does not clear any existing fields/data.
-func setResultsTableHeaders() {
-	th := getResultsTableHeaders()
+func setResultsTableHeaders(extra ResultsExtra) {
+	th := getResultsTableHeaders(extra)
 
 	for i := range th {
 		cell := tview.NewTableCell(fmt.Sprintf("%v%v%v",
@@ -254,10 +431,11 @@ func setResultsTableHeaders() {
 	}
 }
 
-// Constructs and sets the columns for the i'th row in the results table.
-// Unsafe to run repeatedly and does not clear any existing fields/data.
-func setResultsTableCellsForResult(i int, r lib.Result) {
-	td := getResultsTableCell(r)
+// Constructs and sets the columns for the i'th row in the results table,
+// which shows the j'th result. Unsafe to run repeatedly and does not clear
+// any existing fields/data.
+func setResultsTableCellsForResult(i int, r lib.Result, extra ResultsExtra, j int) {
+	td := getResultsTableCell(r, extra, j)
 
 	for j := range td {
 		cell := tview.NewTableCell(fmt.Sprintf("%v%v%v",
@@ -273,30 +451,103 @@ func setResultsTableCellsForResult(i int, r lib.Result) {
 	}
 }
 
+// calculateResults takes the provided profile's transactions, starting balance
+// and start/end dates and generates results, including any interest on the
+// running balance. The interest totals, the balance of each of the profile's
+// accounts, if it has any, the remaining principal of its loans, its net
+// worth, its notable days and its Monte Carlo projection are returned
+// separately. The statusHook is passed directly to the library's
+// result generation function so that prolonged calculations can report their
+// progress; it must not be nil.
+//
+// This is the shared path for both the results page and the headless results
+// command, so it must not depend on any tview primitives.
+func calculateResults(p *Profile, statusHook func(string)) ([]lib.Result, ResultsExtra, error) {
+	bal := getTotalStartingBalance(p)
+
+	st := lib.GetDateString(p.StartYear, p.StartMonth, p.StartDay)
+	end := lib.GetDateString(p.EndYear, p.EndMonth, p.EndDay)
+
+	now := time.Now()
+	startDate := lib.GetDateFromStrSafe(st, now)
+	endDate := lib.GetDateFromStrSafe(end, now)
+
+	holidays, err := getHolidays(&FP.Config)
+	if err != nil {
+		return []lib.Result{}, ResultsExtra{}, fmt.Errorf("%v: %w", FP.T["ResultsGenerationFailed"], err)
+	}
+
+	err = validateTransactionAccounts(p)
+	if err != nil {
+		return []lib.Result{}, ResultsExtra{}, fmt.Errorf("%v: %w", FP.T["ResultsGenerationFailed"], err)
+	}
+
+	err = validateCreditCards(p)
+	if err != nil {
+		return []lib.Result{}, ResultsExtra{}, fmt.Errorf("%v: %w", FP.T["ResultsGenerationFailed"], err)
+	}
+
+	payments, err := getAllCreditCardPayments(p, startDate, endDate, holidays)
+	if err != nil {
+		return []lib.Result{}, ResultsExtra{}, fmt.Errorf("%v: %w", FP.T["ResultsGenerationFailed"], err)
+	}
+
+	transactions := append(slices.Clone(p.TX), payments...)
+
+	txs, err := prepareTransactions(getTotalTransactions(p, transactions), startDate, endDate, holidays)
+	if err != nil {
+		return []lib.Result{}, ResultsExtra{}, fmt.Errorf("%v: %w", FP.T["ResultsGenerationFailed"], err)
+	}
+
+	results, err := lib.GetResults(
+		txs,
+		startDate,
+		endDate,
+		bal,
+		statusHook,
+	)
+	if err != nil {
+		return results, ResultsExtra{}, fmt.Errorf("%v: %w", FP.T["ResultsGenerationFailed"], err)
+	}
+
+	extra := ResultsExtra{}
+
+	// without accounts, interest is simply based on the profile's balance
+	if !hasAccounts(p) {
+		extra.Interest = applyInterest(results, bal, p)
+	} else {
+		extra, err = applyAccounts(results, p, transactions, startDate, endDate, holidays)
+		if err != nil {
+			return results, extra, fmt.Errorf("%v: %w", FP.T["ResultsGenerationFailed"], err)
+		}
+	}
+
+	extra.Loans, err = getLoanBalances(p, results, holidays)
+	if err != nil {
+		return results, extra, fmt.Errorf("%v: %w", FP.T["ResultsGenerationFailed"], err)
+	}
+
+	extra.NetWorth = getNetWorth(p, results, extra)
+	extra.Alerts = getBalanceAlerts(p, results)
+	extra.Goals = getGoalsProgress(p, results, &extra)
+
+	extra.MonteCarlo, err = runMonteCarlo(p, results, transactions, startDate, endDate, holidays)
+	if err != nil {
+		return results, extra, fmt.Errorf("%v: %w", FP.T["ResultsGenerationFailed"], err)
+	}
+
+	return results, extra, nil
+}
+
 // Takes the current profile's transactions + the results form's values (which
 // have been assumed to already have been pushed to the current profile) and
 // generates results. It passes a goroutine statusHook to the library's result
 // generation function so that it can periodically update the status of
 // prolonged calculations for the user to see.
 //
-// You should consider checking the length of the return value and continuing
-// afterwards only if its length is greater than zero.
-func generateResults() []lib.Result {
-	bal := int(lib.ParseDollarAmount(FP.SelectedProfile.StartingBalance, true))
-
-	st := lib.GetDateString(
-		FP.SelectedProfile.StartYear,
-		FP.SelectedProfile.StartMonth,
-		FP.SelectedProfile.StartDay,
-	)
-	end := lib.GetDateString(
-		FP.SelectedProfile.EndYear,
-		FP.SelectedProfile.EndMonth,
-		FP.SelectedProfile.EndDay,
-	)
-
-	var results []lib.Result
-
+// You should consider checking the length of the returned results and
+// continuing afterwards only if its length is greater than zero.
+func generateResults() ([]lib.Result, ResultsExtra) {
 	statusHook := func(status string) {
 		if FP.Config.DisableResultsStatusMessages || FP.ResultsDescription == nil {
 			return
@@ -311,29 +562,18 @@ func generateResults() []lib.Result {
 		})
 	}
 
-	var err error
-
-	now := time.Now()
-
-	results, err = lib.GetResults(
-		FP.SelectedProfile.TX,
-		lib.GetDateFromStrSafe(st, now),
-		lib.GetDateFromStrSafe(end, now),
-		bal,
-		statusHook,
-	)
+	results, extra, err := calculateResults(FP.SelectedProfile, statusHook)
 	if err != nil {
-		FP.ResultsDescription.SetText(fmt.Sprintf("%v%v: %v%v",
+		FP.ResultsDescription.SetText(fmt.Sprintf("%v%v%v",
 			FP.Colors["ResultsDescriptionError"],
-			FP.T["ResultsGenerationFailed"],
 			err.Error(),
 			Reset,
 		))
 
-		return results
+		return results, extra
 	}
 
-	return results
+	return results, extra
 }
 
 // This is basically a callback function that is executed when the results
@@ -342,7 +582,8 @@ func generateResults() []lib.Result {
 //
 // Currently, this function simply updates the results description text view
 // to contain a newline-separated list of all transactions that occurred on
-// the date that is currently highlighted in the results table.
+// the date that is currently highlighted in the results table, or a summary
+// of the highlighted group, if the table is grouped.
 func resultsTableSelectionChanged(row, _ int) {
 	if row <= 0 {
 		return
@@ -350,6 +591,14 @@ func resultsTableSelectionChanged(row, _ int) {
 
 	FP.ResultsDescription.Clear()
 
+	if isResultsGrouped() {
+		if row <= len(FP.ResultsGroups) {
+			FP.ResultsDescription.SetText(getResultsGroupDescription(&FP.ResultsGroups[row-1]))
+		}
+
+		return
+	}
+
 	// ensure there are enough results before trying to show something
 	if len(*(FP.LatestResults))-1 > row-1 {
 		var sb strings.Builder
@@ -400,19 +649,19 @@ func getResultsTable() {
 
 		setSelectedProfileDefaults()
 
-		results := generateResults()
+		results, extra := generateResults()
 
 		garbageCollectPreviousLatestResults()
 
 		FP.LatestResults = &results
+		FP.LatestResultsExtra = extra
 
-		setResultsTableHeaders()
-
-		for i := range results {
-			setResultsTableCellsForResult(i+1, results[i])
-		}
+		setResultsTableRows()
 
 		FP.ResultsTable.SetSelectionChangedFunc(resultsTableSelectionChanged)
+		FP.ResultsTable.SetSelectedFunc(func(row, _ int) {
+			drillDownResultsGroup(row)
+		})
 
 		getResultsStats()
 
@@ -421,3 +670,121 @@ func getResultsTable() {
 		FP.App.SetFocus(FP.ResultsTable)
 	}()
 }
+
+// When the results input field loses focus, either by direct user action or
+// some other event demanding focus elsewhere, this function should be
+// executed.
+func deactivateResultsInputField() {
+	// any in-progress search is over, and the changed func must be removed
+	// before the text is cleared below
+	FP.ResultsSearch.Active = false
+	FP.ResultsInputField.SetChangedFunc(nil)
+
+	FP.ResultsInputField.SetFieldBackgroundColor(
+		tcell.ColorNames[FP.Colors["ResultsInputFieldBlurredBackground"]],
+	)
+
+	FP.ResultsInputField.SetLabel(fmt.Sprintf("%v%v%v",
+		FP.Colors["ResultsInputFieldPassive"],
+		FP.T["ResultsInputFieldPlaceholderLabel"],
+		Reset,
+	))
+
+	FP.ResultsInputField.SetText("")
+	FP.ResultsInputField.SetDoneFunc(nil)
+
+	if FP.App == nil || FP.App.GetFocus() != FP.ResultsInputField {
+		return
+	}
+
+	FP.App.SetFocus(FP.ResultsTable)
+}
+
+// Focuses the results input field, updates its label, and sets its background
+// color to something noticeable.
+func activateResultsInputField(msg, value string) {
+	FP.ResultsSearch.Active = false
+	FP.ResultsInputField.SetChangedFunc(nil)
+
+	FP.ResultsInputField.SetFieldBackgroundColor(
+		tcell.ColorNames[FP.Colors["ResultsInputFieldFocusedBackground"]],
+	)
+
+	FP.ResultsInputField.SetLabel(fmt.Sprintf("%v%v%v",
+		FP.Colors["ResultsInputFieldActive"],
+		msg,
+		Reset,
+	))
+
+	FP.ResultsInputField.SetText(value)
+
+	FP.App.SetFocus(FP.ResultsInputField)
+}
+
+// Returns a reasonable default file name for exporting the latest results,
+// such as "My Profile 2024-01-01 2025-01-01.csv".
+func getDefaultResultsExportFileName() string {
+	return fmt.Sprintf("%v %v %v.csv",
+		FP.SelectedProfile.Name,
+		lib.GetDateString(FP.SelectedProfile.StartYear, FP.SelectedProfile.StartMonth, FP.SelectedProfile.StartDay),
+		lib.GetDateString(FP.SelectedProfile.EndYear, FP.SelectedProfile.EndMonth, FP.SelectedProfile.EndDay),
+	)
+}
+
+// Prompts the user for a file path and writes the latest results to it. The
+// format is chosen based on the file extension.
+func promptExportResults() {
+	if FP.CalculatingResults || FP.LatestResults == nil || len(*(FP.LatestResults)) == 0 {
+		FP.ResultsDescription.SetText(fmt.Sprintf("%v%v%v",
+			FP.Colors["ResultsDescriptionError"],
+			FP.T["ResultsExportNothingToExport"],
+			Reset,
+		))
+
+		return
+	}
+
+	FP.ResultsInputField.SetDoneFunc(func(key tcell.Key) {
+		switch key {
+		case tcell.KeyEscape:
+			deactivateResultsInputField()
+
+			return
+		default:
+			file := strings.TrimSpace(FP.ResultsInputField.GetText())
+			if getFormatFromPath(file) == "" {
+				FP.ResultsInputField.SetLabel(fmt.Sprintf("%v%v:%v",
+					FP.Colors["ResultsInputFieldActive"],
+					FP.T["ResultsExportInvalidExtensionLabel"],
+					Reset,
+				))
+
+				return
+			}
+
+			err := exportResults(file, *(FP.LatestResults), FP.LatestResultsExtra)
+			if err != nil {
+				FP.ResultsDescription.SetText(fmt.Sprintf("%v%v: %v%v",
+					FP.Colors["ResultsDescriptionError"],
+					FP.T["ResultsExportFailed"],
+					err.Error(),
+					Reset,
+				))
+			} else {
+				FP.ResultsDescription.SetText(fmt.Sprintf("%v%v: %v%v",
+					FP.Colors["ResultsDescriptionPassive"],
+					FP.T["ResultsExportSucceeded"],
+					file,
+					Reset,
+				))
+			}
+
+			deactivateResultsInputField()
+		}
+	})
+
+	activateResultsInputField(
+		fmt.Sprintf("%v:", FP.T["ResultsExportPathLabel"]),
+		getDefaultResultsExportFileName(),
+	)
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	lib "github.com/charles-m-knox/finance-planner-lib"
+)
+
+// This file contains the logic for amount schedules, which change a
+// transaction's amount over time, either in steps on given dates or by a
+// percentage every year.
+
+// The separator between the entries of an amount schedule when it is edited
+// as text.
+const AmountScheduleSeparator = ";"
+
+// AmountChange is a scheduled change to a transaction's amount. From Date
+// (formatted as YYYY-MM-DD) onwards, the transaction's amount is Amount.
+type AmountChange struct {
+	Date   string `yaml:"date"`
+	Amount int    `yaml:"amount"`
+}
+
+// AmountStep is the parsed form of an AmountChange.
+type AmountStep struct {
+	Date   time.Time
+	Amount int
+}
+
+// hasAmountSchedule returns true if the transaction's amount changes over
+// time.
+func hasAmountSchedule(tx *TX) bool {
+	return len(tx.AmountChanges) > 0 || tx.Escalation != 0
+}
+
+// getAmountSteps returns the transaction's amount changes in chronological
+// order, starting with its own amount on its start date (or start, if it is
+// unset). Escalation needs a start date to compound from, so that the amounts
+// don't depend on the dates of the results.
+func getAmountSteps(tx *TX, start time.Time) ([]AmountStep, error) {
+	if tx.Escalation != 0 && !hasTXStartDate(tx) {
+		return nil, fmt.Errorf("%v", FP.T["AmountScheduleEscalationNoStartDate"])
+	}
+
+	txStart, _ := getTXDateRange(tx, start, MaxFinalOccurrenceDate)
+	steps := []AmountStep{{Date: txStart, Amount: tx.Amount}}
+
+	for _, c := range tx.AmountChanges {
+		d, err := time.Parse(time.DateOnly, strings.TrimSpace(c.Date))
+		if err != nil {
+			return nil, fmt.Errorf("%v %v: %w", FP.T["AmountScheduleInvalidDate"], c.Date, err)
+		}
+
+		steps = append(steps, AmountStep{Date: d, Amount: c.Amount})
+	}
+
+	slices.SortStableFunc(steps[1:], func(a, b AmountStep) int {
+		return a.Date.Compare(b.Date)
+	})
+
+	return steps, nil
+}
+
+// getFullYears returns the number of whole years from since until d.
+func getFullYears(since, d time.Time) int {
+	years := d.Year() - since.Year()
+	if d.Before(since.AddDate(years, 0, 0)) {
+		years--
+	}
+
+	return max(years, 0)
+}
+
+// getAmountOn returns the amount of a transaction on the given date. The
+// latest step on or before d applies, and escalation (a percentage) compounds
+// once every year since that step's date.
+func getAmountOn(steps []AmountStep, escalation float64, d time.Time) int {
+	step := steps[0]
+
+	for i := range steps {
+		if steps[i].Date.After(d) {
+			break
+		}
+
+		step = steps[i]
+	}
+
+	if escalation == 0 {
+		return step.Amount
+	}
+
+	years := getFullYears(step.Date, d)
+
+	return int(math.Round(float64(step.Amount) * math.Pow(1+escalation/100, float64(years))))
+}
+
+// formatEditableAmount formats an amount so that it can be parsed again with
+// lib.ParseDollarAmount, which assumes that amounts are negative unless they
+// start with a +.
+func formatEditableAmount(amount int) string {
+	a := lib.FormatAsCurrency(amount)
+	if amount >= 0 {
+		a = fmt.Sprintf("+%v", a)
+	}
+
+	return a
+}
+
+// formatAmountSchedule formats the transaction's amount schedule as text, such
+// as "3%; 2025-01-01 $-20.00; sd $15.00". It can be parsed with
+// parseAmountSchedule.
+func formatAmountSchedule(tx *TX) string {
+	entries := []string{}
+
+	if u := formatUncertainty(tx); u != "" {
+		entries = append(entries, u)
+	}
+
+	if tx.Escalation != 0 {
+		entries = append(entries, fmt.Sprintf("%v%%", strconv.FormatFloat(tx.Escalation, 'f', -1, 64)))
+	}
+
+	for _, c := range tx.AmountChanges {
+		entries = append(entries, fmt.Sprintf("%v %v", c.Date, formatEditableAmount(c.Amount)))
+	}
+
+	return strings.Join(entries, fmt.Sprintf("%v ", AmountScheduleSeparator))
+}
+
+// parseAmountSchedule parses an amount schedule that was formatted with
+// formatAmountSchedule. Each entry is either an escalation percentage per year,
+// such as "3%", a date and the amount from that date onwards, such as
+// "2025-01-01 $-20.00", or how much the amount varies (see parseUncertainty).
+// The amount changes are returned sorted by date.
+func parseAmountSchedule(s string) ([]AmountChange, float64, Uncertainty, error) {
+	changes := []AmountChange{}
+	escalation := 0.0
+	hasEscalation := false
+	uncertainty := Uncertainty{}
+	hasUncertainty := false
+
+	for _, entry := range strings.Split(s, AmountScheduleSeparator) {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		u, ok, err := parseUncertainty(entry)
+		if ok && err == nil && hasUncertainty {
+			err = fmt.Errorf("%v: %v", FP.T["UncertaintyDuplicate"], entry)
+		}
+
+		if err != nil {
+			return nil, 0, Uncertainty{}, err
+		}
+
+		if ok {
+			uncertainty = u
+			hasUncertainty = true
+
+			continue
+		}
+
+		if strings.HasSuffix(entry, "%") {
+			e, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(entry, "%")), 64)
+			if err != nil || hasEscalation || e <= -100 {
+				return nil, 0, Uncertainty{}, fmt.Errorf("%v: %v", FP.T["AmountScheduleInvalidEscalation"], entry)
+			}
+
+			escalation = e
+			hasEscalation = true
+
+			continue
+		}
+
+		fields := strings.Fields(entry)
+		if len(fields) != 2 {
+			return nil, 0, Uncertainty{}, fmt.Errorf("%v: %v", FP.T["AmountScheduleInvalidEntry"], entry)
+		}
+
+		d, err := time.Parse(time.DateOnly, fields[0])
+		if err != nil {
+			return nil, 0, Uncertainty{}, fmt.Errorf("%v: %v", FP.T["AmountScheduleInvalidDate"], fields[0])
+		}
+
+		changes = append(changes, AmountChange{
+			Date:   d.Format(time.DateOnly),
+			Amount: int(lib.ParseDollarAmount(fields[1], false)),
+		})
+	}
+
+	slices.SortStableFunc(changes, func(a, b AmountChange) int {
+		return strings.Compare(a.Date, b.Date)
+	})
+
+	return changes, escalation, uncertainty, nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetAmountOn(t *testing.T) {
+	date := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	// a transaction of $-100.00 that starts on 2025-03-01
+	tx := getNewTX(date(2025, time.March, 1))
+	tx.Amount = -10000
+
+	tests := []struct {
+		name          string
+		escalation    float64
+		amountChanges []AmountChange
+		// whether the transaction has a start date
+		noStartDate bool
+		d           time.Time
+		want        int
+		wantErr     bool
+	}{
+		{
+			name: "no schedule",
+			d:    date(2030, time.January, 1),
+			want: -10000,
+		},
+		{
+			name:       "escalation before a full year",
+			escalation: 3,
+			d:          date(2026, time.February, 28),
+			want:       -10000,
+		},
+		{
+			name:       "escalation after a full year",
+			escalation: 3,
+			d:          date(2026, time.March, 1),
+			want:       -10300,
+		},
+		{
+			name:       "escalation compounds",
+			escalation: 3,
+			d:          date(2027, time.March, 1),
+			want:       -10609,
+		},
+		{
+			name:          "amount change",
+			amountChanges: []AmountChange{{Date: "2025-06-01", Amount: -12000}},
+			d:             date(2025, time.June, 1),
+			want:          -12000,
+		},
+		{
+			name:          "escalation compounds from the latest amount change",
+			escalation:    10,
+			amountChanges: []AmountChange{{Date: "2025-06-01", Amount: -12000}},
+			d:             date(2026, time.May, 31),
+			want:          -12000,
+		},
+		{
+			name:        "amount change without a start date",
+			noStartDate: true,
+			amountChanges: []AmountChange{
+				{Date: "2025-06-01", Amount: -12000},
+			},
+			d:    date(2026, time.August, 1),
+			want: -12000,
+		},
+		{
+			name:        "escalation without a start date",
+			escalation:  3,
+			noStartDate: true,
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := tx
+			tx.Escalation = tt.escalation
+			tx.AmountChanges = tt.amountChanges
+
+			if tt.noStartDate {
+				tx.StartsYear, tx.StartsMonth, tx.StartsDay = 0, 0, 0
+			}
+
+			// the amounts must not depend on the start of the results
+			for _, start := range []time.Time{date(2025, time.January, 1), date(2026, time.July, 15)} {
+				steps, err := getAmountSteps(&tx, start)
+				if tt.wantErr {
+					if err == nil {
+						t.Fatalf("got no error, want one")
+					}
+
+					return
+				}
+
+				if err != nil {
+					t.Fatalf("failed to get amount steps: %v", err)
+				}
+
+				if got := getAmountOn(steps, tx.Escalation, tt.d); got != tt.want {
+					t.Errorf("got %v from results starting %v, want %v", got, start.Format(time.DateOnly), tt.want)
+				}
+			}
+		})
+	}
+}
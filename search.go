@@ -0,0 +1,295 @@
+package main
+
+import (
+	"fmt"
+
+	lib "github.com/charles-m-knox/finance-planner-lib"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/lithammer/fuzzysearch/fuzzy"
+	"github.com/rivo/tview"
+)
+
+// TableSearch holds the state of an incremental fuzzy search within a table.
+type TableSearch struct {
+	// The text that the user has typed into the search input field. When
+	// empty, nothing is highlighted.
+	Query string
+
+	// The table rows (including the header row offset) that match the query,
+	// in ascending order.
+	Rows []int
+
+	// The index in Rows of the currently selected match.
+	Pos int
+
+	// True while the user is typing into the search input field.
+	Active bool
+}
+
+// txMatchesSearch returns true if the transaction's name or note fuzzily
+// matches the query (case-insensitive).
+func txMatchesSearch(tx TX, query string) bool {
+	if query == "" {
+		return false
+	}
+
+	return fuzzy.MatchFold(query, tx.Name) || fuzzy.MatchFold(query, tx.Note)
+}
+
+// resultMatchesSearch returns true if the result's date or any of the day's
+// transaction names fuzzily match the query (case-insensitive).
+func resultMatchesSearch(r lib.Result, query string) bool {
+	if query == "" {
+		return false
+	}
+
+	if fuzzy.MatchFold(query, lib.GetNowDateString(r.Date)) {
+		return true
+	}
+
+	for _, name := range r.DayTransactionNamesSlice {
+		if fuzzy.MatchFold(query, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getSearchLabel returns the input field label for a search, including the
+// position of the currently selected match, such as "search [2/5]:".
+func getSearchLabel(s *TableSearch) string {
+	pos := 0
+	if len(s.Rows) > 0 {
+		pos = s.Pos + 1
+	}
+
+	return fmt.Sprintf("%v [%v/%v]:", FP.T["SearchInputFieldLabel"], pos, len(s.Rows))
+}
+
+// selectSearchMatch moves the table selection to the s.Pos'th match, keeping
+// the currently selected column.
+func selectSearchMatch(s *TableSearch, table *tview.Table) {
+	if len(s.Rows) == 0 {
+		return
+	}
+
+	if s.Pos < 0 || s.Pos >= len(s.Rows) {
+		s.Pos = 0
+	}
+
+	_, cc := table.GetSelection()
+	table.Select(s.Rows[s.Pos], cc)
+}
+
+// nextSearchMatch moves to the next (or previous, if forward is false) match
+// relative to the currently selected row, wrapping around at either end.
+func nextSearchMatch(s *TableSearch, table *tview.Table, forward bool) {
+	if len(s.Rows) == 0 {
+		return
+	}
+
+	cr, _ := table.GetSelection()
+
+	if forward {
+		s.Pos = 0
+
+		for i, row := range s.Rows {
+			if row > cr {
+				s.Pos = i
+
+				break
+			}
+		}
+	} else {
+		s.Pos = len(s.Rows) - 1
+
+		for i := len(s.Rows) - 1; i >= 0; i-- {
+			if s.Rows[i] < cr {
+				s.Pos = i
+
+				break
+			}
+		}
+	}
+
+	selectSearchMatch(s, table)
+}
+
+// updateTransactionsSearchRows recomputes which rows of the transactions table
+// match the current search query.
+func updateTransactionsSearchRows() {
+	FP.TransactionsSearch.Rows = []int{}
+
+	if FP.SelectedProfile == nil || FP.TransactionsSearch.Query == "" {
+		return
+	}
+
+	for i := range FP.SelectedProfile.TX {
+		if txMatchesSearch(FP.SelectedProfile.TX[i], FP.TransactionsSearch.Query) {
+			FP.TransactionsSearch.Rows = append(FP.TransactionsSearch.Rows, i+1)
+		}
+	}
+}
+
+// setTransactionsSearchLabel updates the transactions input field's label to
+// show the current search match position.
+func setTransactionsSearchLabel() {
+	FP.TransactionsInputField.SetLabel(fmt.Sprintf("%v%v%v",
+		FP.Colors["TransactionsInputFieldActive"],
+		getSearchLabel(&FP.TransactionsSearch),
+		Reset,
+	))
+}
+
+// startTransactionsSearch activates the transactions input field for an
+// incremental search. Matches are highlighted as the user types; Enter keeps
+// the highlights and returns focus to the table, while Escape clears them.
+func startTransactionsSearch() {
+	s := &FP.TransactionsSearch
+
+	done := func(keep bool) {
+		if !keep {
+			s.Query = ""
+		}
+
+		deactivateTransactionsInputField()
+		getTransactionsTable()
+		selectSearchMatch(s, FP.TransactionsTable)
+		FP.App.SetFocus(FP.TransactionsTable)
+	}
+
+	FP.TransactionsInputField.SetDoneFunc(func(key tcell.Key) {
+		switch key {
+		case tcell.KeyEscape:
+			done(false)
+		default:
+			done(true)
+		}
+	})
+
+	activateTransactionsInputField(getSearchLabel(s), s.Query)
+
+	s.Active = true
+
+	FP.TransactionsInputField.SetChangedFunc(func(text string) {
+		s.Query = text
+		s.Pos = 0
+
+		getTransactionsTable()
+		selectSearchMatch(s, FP.TransactionsTable)
+
+		setTransactionsSearchLabel()
+	})
+}
+
+// applyResultsSearch recomputes which rows of the results table match the
+// current search query and updates their background colors accordingly. Rows
+// that don't match keep their low balance or drill-down highlighting; see
+// getResultsDayRowColor. When the table is grouped, a group matches if any of
+// its days match.
+func applyResultsSearch() {
+	s := &FP.ResultsSearch
+	s.Rows = []int{}
+
+	if FP.LatestResults == nil {
+		return
+	}
+
+	match := tcell.GetColor(FP.Colors["ResultsRowSearchMatchColor"])
+	results := *(FP.LatestResults)
+
+	setRowColor := func(row int, bg tcell.Color) {
+		for j := 0; j < FP.ResultsTable.GetColumnCount(); j++ {
+			cell := FP.ResultsTable.GetCell(row, j)
+			if cell == nil {
+				continue
+			}
+
+			cell.SetBackgroundColor(bg)
+		}
+	}
+
+	if isResultsGrouped() {
+		for g := range FP.ResultsGroups {
+			row := g + 1
+			bg := getResultsGroupRowColor(&FP.ResultsGroups[g])
+
+			for i := FP.ResultsGroups[g].First; i <= FP.ResultsGroups[g].Last; i++ {
+				if resultMatchesSearch(results[i], s.Query) {
+					s.Rows = append(s.Rows, row)
+					bg = match
+
+					break
+				}
+			}
+
+			setRowColor(row, bg)
+		}
+
+		return
+	}
+
+	for i := range results {
+		row := i + 1
+		bg := getResultsDayRowColor(results, i)
+
+		if resultMatchesSearch(results[i], s.Query) {
+			s.Rows = append(s.Rows, row)
+			bg = match
+		}
+
+		setRowColor(row, bg)
+	}
+}
+
+// setResultsSearchLabel updates the results input field's label to show the
+// current search match position.
+func setResultsSearchLabel() {
+	FP.ResultsInputField.SetLabel(fmt.Sprintf("%v%v%v",
+		FP.Colors["ResultsInputFieldActive"],
+		getSearchLabel(&FP.ResultsSearch),
+		Reset,
+	))
+}
+
+// startResultsSearch activates the results input field for an incremental
+// search. It behaves the same as startTransactionsSearch.
+func startResultsSearch() {
+	s := &FP.ResultsSearch
+
+	done := func(keep bool) {
+		if !keep {
+			s.Query = ""
+		}
+
+		deactivateResultsInputField()
+		applyResultsSearch()
+		selectSearchMatch(s, FP.ResultsTable)
+		FP.App.SetFocus(FP.ResultsTable)
+	}
+
+	FP.ResultsInputField.SetDoneFunc(func(key tcell.Key) {
+		switch key {
+		case tcell.KeyEscape:
+			done(false)
+		default:
+			done(true)
+		}
+	})
+
+	activateResultsInputField(getSearchLabel(s), s.Query)
+
+	s.Active = true
+
+	FP.ResultsInputField.SetChangedFunc(func(text string) {
+		s.Query = text
+		s.Pos = 0
+
+		applyResultsSearch()
+		selectSearchMatch(s, FP.ResultsTable)
+
+		setResultsSearchLabel()
+	})
+}
@@ -0,0 +1,572 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	lib "github.com/charles-m-knox/finance-planner-lib"
+
+	"github.com/rivo/tview"
+	"gopkg.in/yaml.v3"
+)
+
+// This file contains the undo history page, which lists every snapshot in the
+// undo buffer alongside a summary of what changed since the snapshot before
+// it, shows a preview of the full diff for the highlighted snapshot, and
+// allows jumping directly to any snapshot.
+//
+// Snapshots are compared as generic yaml maps rather than as Config structs,
+// so that every field shows up in the diff without needing to be listed here.
+
+const (
+	ChangeAdded   = "+"
+	ChangeRemoved = "-"
+	ChangeEdited  = "~"
+)
+
+// FieldChange is a single field that differs between two snapshots.
+type FieldChange struct {
+	Field string
+	Old   string
+	New   string
+}
+
+// SnapshotChange describes something that was added, removed or edited
+// between two consecutive snapshots in the undo buffer.
+type SnapshotChange struct {
+	// One of ChangeAdded, ChangeRemoved, or ChangeEdited.
+	Kind string
+
+	// The name of the profile that this change belongs to. Empty for changes
+	// to config-level settings.
+	Profile string
+
+	// The ID of the transaction that this change belongs to. Empty for
+	// changes to the profile itself, or to config-level settings.
+	ID string
+
+	// The name of the transaction, if ID is set.
+	Name string
+
+	// Only populated for edits.
+	Fields []FieldChange
+}
+
+// getSnapshot decompresses (if needed) and parses the i'th snapshot in the
+// undo buffer into a generic map.
+func getSnapshot(i int) (map[string]any, error) {
+	var err error
+
+	b := FP.UndoBuffer[i]
+
+	if !FP.Config.DisableGzipCompressionInUndoBuffer {
+		b, err = decompress(b)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %w", FP.T["UndoBufferConfigDecompressionError"], err)
+		}
+	}
+
+	m := map[string]any{}
+
+	err = yaml.Unmarshal(b, &m)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", FP.T["UndoBufferPushValueConfigUnmarshalFailure"], err)
+	}
+
+	return m, nil
+}
+
+// getMapSlice converts a generic yaml sequence of mappings into a slice of
+// maps, skipping anything that isn't a mapping.
+func getMapSlice(v any) []map[string]any {
+	s, ok := v.([]any)
+	if !ok {
+		return []map[string]any{}
+	}
+
+	result := make([]map[string]any, 0, len(s))
+
+	for i := range s {
+		m, ok := s[i].(map[string]any)
+		if ok {
+			result = append(result, m)
+		}
+	}
+
+	return result
+}
+
+// getMapString returns the string value of key in m, or an empty string.
+func getMapString(m map[string]any, key string) string {
+	v, ok := m[key]
+	if !ok || v == nil {
+		return ""
+	}
+
+	return fmt.Sprint(v)
+}
+
+// formatSnapshotValue renders a generic yaml value for display in a diff.
+func formatSnapshotValue(field string, v any) string {
+	if v == nil {
+		return ""
+	}
+
+	if n, ok := v.(int); ok && field == "amount" {
+		return lib.FormatAsCurrency(n)
+	}
+
+	if t, ok := v.(time.Time); ok {
+		return t.Format(time.DateTime)
+	}
+
+	return fmt.Sprint(v)
+}
+
+// diffFields compares every field in before and after, except for the fields in
+// skip, and returns the fields that differ, sorted by field name.
+func diffFields(before, after map[string]any, skip ...string) []FieldChange {
+	keys := []string{}
+
+	for k := range before {
+		keys = append(keys, k)
+	}
+
+	for k := range after {
+		if _, ok := before[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+
+	slices.Sort(keys)
+
+	changes := []FieldChange{}
+
+	for _, k := range keys {
+		if slices.Contains(skip, k) {
+			continue
+		}
+
+		o := formatSnapshotValue(k, before[k])
+		n := formatSnapshotValue(k, after[k])
+
+		if o != n {
+			changes = append(changes, FieldChange{Field: k, Old: o, New: n})
+		}
+	}
+
+	return changes
+}
+
+// indexMapSlice returns a lookup of the provided maps by the value of key. If
+// multiple maps share the same value, the first one wins.
+func indexMapSlice(s []map[string]any, key string) map[string]map[string]any {
+	index := make(map[string]map[string]any, len(s))
+
+	for i := range s {
+		k := getMapString(s[i], key)
+		if _, ok := index[k]; !ok {
+			index[k] = s[i]
+		}
+	}
+
+	return index
+}
+
+// diffTransactions compares the transactions of a profile between two
+// snapshots, matching transactions by their ID.
+func diffTransactions(profile string, before, after []map[string]any) []SnapshotChange {
+	changes := []SnapshotChange{}
+	oldIndex := indexMapSlice(before, "id")
+	newIndex := indexMapSlice(after, "id")
+
+	oldOrder := []string{}
+	newOrder := []string{}
+
+	for i := range after {
+		id := getMapString(after[i], "id")
+
+		o, ok := oldIndex[id]
+		if !ok {
+			changes = append(changes, SnapshotChange{
+				Kind:    ChangeAdded,
+				Profile: profile,
+				ID:      id,
+				Name:    getMapString(after[i], "name"),
+			})
+
+			continue
+		}
+
+		newOrder = append(newOrder, id)
+
+		fields := diffFields(o, after[i])
+		if len(fields) > 0 {
+			changes = append(changes, SnapshotChange{
+				Kind:    ChangeEdited,
+				Profile: profile,
+				ID:      id,
+				Name:    getMapString(after[i], "name"),
+				Fields:  fields,
+			})
+		}
+	}
+
+	for i := range before {
+		id := getMapString(before[i], "id")
+
+		if _, ok := newIndex[id]; !ok {
+			changes = append(changes, SnapshotChange{
+				Kind:    ChangeRemoved,
+				Profile: profile,
+				ID:      id,
+				Name:    getMapString(before[i], "name"),
+			})
+
+			continue
+		}
+
+		oldOrder = append(oldOrder, id)
+	}
+
+	if !slices.Equal(oldOrder, newOrder) {
+		changes = append(changes, SnapshotChange{
+			Kind:    ChangeEdited,
+			Profile: profile,
+			Fields:  []FieldChange{{Field: FP.T["SnapshotTransactionsReordered"]}},
+		})
+	}
+
+	return changes
+}
+
+// diffSnapshots returns everything that changed between two snapshots.
+// Profiles are matched by name, and transactions by ID.
+func diffSnapshots(before, after map[string]any) []SnapshotChange {
+	changes := []SnapshotChange{}
+
+	fields := diffFields(before, after, "profiles")
+	if len(fields) > 0 {
+		changes = append(changes, SnapshotChange{Kind: ChangeEdited, Fields: fields})
+	}
+
+	oldProfiles := getMapSlice(before["profiles"])
+	newProfiles := getMapSlice(after["profiles"])
+	oldIndex := indexMapSlice(oldProfiles, "name")
+	newIndex := indexMapSlice(newProfiles, "name")
+
+	for i := range newProfiles {
+		name := getMapString(newProfiles[i], "name")
+
+		o, ok := oldIndex[name]
+		if !ok {
+			changes = append(changes, SnapshotChange{Kind: ChangeAdded, Profile: name})
+
+			continue
+		}
+
+		fields := diffFields(o, newProfiles[i], "transactions")
+		if len(fields) > 0 {
+			changes = append(changes, SnapshotChange{Kind: ChangeEdited, Profile: name, Fields: fields})
+		}
+
+		changes = append(changes, diffTransactions(
+			name,
+			getMapSlice(o["transactions"]),
+			getMapSlice(newProfiles[i]["transactions"]),
+		)...)
+	}
+
+	for i := range oldProfiles {
+		name := getMapString(oldProfiles[i], "name")
+
+		if _, ok := newIndex[name]; !ok {
+			changes = append(changes, SnapshotChange{Kind: ChangeRemoved, Profile: name})
+		}
+	}
+
+	return changes
+}
+
+// getSnapshotChanges returns the changes between the i'th snapshot and the
+// one before it. The first snapshot in the buffer has no changes.
+func getSnapshotChanges(i int) ([]SnapshotChange, error) {
+	if i <= 0 {
+		return []SnapshotChange{}, nil
+	}
+
+	before, err := getSnapshot(i - 1)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := getSnapshot(i)
+	if err != nil {
+		return nil, err
+	}
+
+	return diffSnapshots(before, after), nil
+}
+
+// getSnapshotSummary condenses a list of changes into a single line, such as
+// "Default: +1 -0 ~2; settings".
+func getSnapshotSummary(changes []SnapshotChange) string {
+	if len(changes) == 0 {
+		return FP.T["SnapshotNoChanges"]
+	}
+
+	type counts struct{ added, removed, edited int }
+
+	profiles := []string{}
+	perProfile := map[string]*counts{}
+	parts := []string{}
+
+	for _, c := range changes {
+		switch {
+		case c.Profile == "":
+			parts = append(parts, FP.T["SnapshotSettings"])
+		case c.ID == "" && c.Kind != ChangeEdited:
+			parts = append(parts, fmt.Sprintf("%v%v %v", c.Kind, FP.T["SnapshotProfile"], c.Profile))
+		default:
+			pc, ok := perProfile[c.Profile]
+			if !ok {
+				pc = &counts{}
+				perProfile[c.Profile] = pc
+				profiles = append(profiles, c.Profile)
+			}
+
+			switch c.Kind {
+			case ChangeAdded:
+				pc.added++
+			case ChangeRemoved:
+				pc.removed++
+			default:
+				pc.edited++
+			}
+		}
+	}
+
+	for _, p := range profiles {
+		pc := perProfile[p]
+		parts = append(parts, fmt.Sprintf("%v: +%v -%v ~%v", p, pc.added, pc.removed, pc.edited))
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+// getSnapshotChangeColor returns the theme color for the kind of change.
+func getSnapshotChangeColor(kind string) string {
+	switch kind {
+	case ChangeAdded:
+		return FP.Colors["SnapshotChangeAdded"]
+	case ChangeRemoved:
+		return FP.Colors["SnapshotChangeRemoved"]
+	default:
+		return FP.Colors["SnapshotChangeEdited"]
+	}
+}
+
+// getSnapshotPreview renders the full list of changes for the preview pane.
+func getSnapshotPreview(changes []SnapshotChange) string {
+	if len(changes) == 0 {
+		return fmt.Sprintf("%v%v%v", FP.Colors["SnapshotPreviewPassive"], FP.T["SnapshotNoChanges"], Reset)
+	}
+
+	var sb strings.Builder
+
+	for _, c := range changes {
+		color := getSnapshotChangeColor(c.Kind)
+
+		switch {
+		case c.Profile == "":
+			sb.WriteString(fmt.Sprintf("%v%v %v%v\n", color, c.Kind, FP.T["SnapshotSettings"], Reset))
+		case c.ID == "":
+			sb.WriteString(fmt.Sprintf("%v%v %v %v%v\n", color, c.Kind, FP.T["SnapshotProfile"], tview.Escape(c.Profile), Reset))
+		default:
+			sb.WriteString(fmt.Sprintf("%v%v %v / %v %v%v\n",
+				color,
+				c.Kind,
+				tview.Escape(c.Profile),
+				tview.Escape(c.ID),
+				tview.Escape(c.Name),
+				Reset,
+			))
+		}
+
+		for _, f := range c.Fields {
+			if f.Old == "" && f.New == "" {
+				sb.WriteString(fmt.Sprintf("    %v\n", tview.Escape(f.Field)))
+
+				continue
+			}
+
+			sb.WriteString(fmt.Sprintf("    %v: %v%v%v -> %v%v%v\n",
+				tview.Escape(f.Field),
+				FP.Colors["SnapshotChangeRemoved"],
+				tview.Escape(f.Old),
+				Reset,
+				FP.Colors["SnapshotChangeAdded"],
+				tview.Escape(f.New),
+				Reset,
+			))
+		}
+	}
+
+	return sb.String()
+}
+
+// getSnapshotTime returns the formatted time at which the i'th snapshot was
+// taken.
+func getSnapshotTime(i int) string {
+	if i < 0 || i >= len(FP.UndoBufferTimes) || FP.UndoBufferTimes[i].IsZero() {
+		return ""
+	}
+
+	return FP.UndoBufferTimes[i].Format(time.DateTime)
+}
+
+// This should only ever be called once, upon application startup.
+//
+// returns a simple flex view with two columns:
+// - a table of every snapshot in the undo buffer (left side)
+// - a preview of the changes made in the highlighted snapshot (right side).
+func getHistoryPage() *tview.Flex {
+	FP.HistoryTable = tview.NewTable().SetFixed(1, 0)
+	FP.HistoryTable.SetBorder(true)
+	FP.HistoryTable.SetTitle(FP.T["HistoryTableTitle"])
+	FP.HistoryTable.SetBorders(false).
+		SetSelectable(true, false).
+		SetSeparator(' ')
+
+	FP.HistoryPreview = tview.NewTextView().SetDynamicColors(true)
+	FP.HistoryPreview.SetBorder(true)
+	FP.HistoryPreview.SetTitle(FP.T["HistoryPreviewTitle"])
+
+	FP.HistoryTable.SetSelectionChangedFunc(func(row, _ int) {
+		setHistoryPreview(row - 1)
+	})
+
+	FP.HistoryTable.SetSelectedFunc(func(row, _ int) {
+		jumpToSnapshot(row - 1)
+	})
+
+	return tview.NewFlex().SetDirection(tview.FlexColumn).
+		AddItem(FP.HistoryTable, 0, 1, true).
+		AddItem(FP.HistoryPreview, 0, 1, false)
+}
+
+// getHistoryTable clears and re-populates the undo history table, and
+// selects the row for the current undo buffer position.
+func getHistoryTable() {
+	FP.HistoryTable.Clear()
+
+	headers := []TableCell{
+		{Text: FP.T["HistoryColumnPosition"], Color: FP.Colors["HistoryColumnPosition"]},
+		{Text: FP.T["HistoryColumnTime"], Color: FP.Colors["HistoryColumnTime"]},
+		{Text: FP.T["HistoryColumnSummary"], Color: FP.Colors["HistoryColumnSummary"], Expand: 1},
+	}
+
+	for i := range headers {
+		cell := tview.NewTableCell(fmt.Sprintf("%v%v%v", headers[i].Color, headers[i].Text, Reset))
+		if headers[i].Expand > 0 {
+			cell.SetExpansion(headers[i].Expand)
+		}
+
+		FP.HistoryTable.SetCell(0, i, cell)
+	}
+
+	for i := range FP.UndoBuffer {
+		summary := ""
+
+		changes, err := getSnapshotChanges(i)
+		if err != nil {
+			summary = fmt.Sprintf("%v%v%v", FP.Colors["SnapshotError"], tview.Escape(err.Error()), Reset)
+		} else if i == 0 {
+			summary = FP.T["SnapshotOldest"]
+		} else {
+			summary = tview.Escape(getSnapshotSummary(changes))
+		}
+
+		pos := fmt.Sprintf("%v/%v", i+1, len(FP.UndoBuffer))
+		if i == FP.UndoBufferPos {
+			pos = fmt.Sprintf("%v%v*%v", pos, FP.Colors["HistoryCurrentMarker"], Reset)
+		}
+
+		cells := []TableCell{
+			{Text: pos, Color: FP.Colors["HistoryColumnPosition"]},
+			{Text: getSnapshotTime(i), Color: FP.Colors["HistoryColumnTime"]},
+			{Text: summary, Color: FP.Colors["HistoryColumnSummary"], Expand: 1},
+		}
+
+		for j := range cells {
+			cell := tview.NewTableCell(fmt.Sprintf("%v%v%v", cells[j].Color, cells[j].Text, Reset))
+			if cells[j].Expand > 0 {
+				cell.SetExpansion(cells[j].Expand)
+			}
+
+			FP.HistoryTable.SetCell(i+1, j, cell)
+		}
+	}
+
+	FP.HistoryTable.Select(FP.UndoBufferPos+1, 0)
+	setHistoryPreview(FP.UndoBufferPos)
+}
+
+// setHistoryPreview shows the changes made in the i'th snapshot in the
+// preview pane.
+func setHistoryPreview(i int) {
+	if i < 0 || i >= len(FP.UndoBuffer) {
+		FP.HistoryPreview.Clear()
+
+		return
+	}
+
+	changes, err := getSnapshotChanges(i)
+	if err != nil {
+		FP.HistoryPreview.SetText(fmt.Sprintf("%v%v%v", FP.Colors["SnapshotError"], tview.Escape(err.Error()), Reset))
+
+		return
+	}
+
+	FP.HistoryPreview.SetText(fmt.Sprintf("%v%v %v/%v %v%v\n\n%v",
+		FP.Colors["SnapshotPreviewPassive"],
+		FP.T["HistorySnapshot"],
+		i+1,
+		len(FP.UndoBuffer),
+		getSnapshotTime(i),
+		Reset,
+		getSnapshotPreview(changes),
+	))
+	FP.HistoryPreview.ScrollToBeginning()
+}
+
+// jumpToSnapshot moves the undo buffer directly to the i'th snapshot and
+// loads it, as if undo or redo had been pressed repeatedly.
+func jumpToSnapshot(i int) {
+	if i < 0 || i >= len(FP.UndoBuffer) {
+		return
+	}
+
+	FP.UndoBufferPos = i
+
+	pushUndoBufferChangeToConfig()
+
+	FP.ProfileStatusText.SetText(fmt.Sprintf(
+		"%v%v: [%v/%v]%v",
+		FP.Colors["ProfileStatusTextPassive"],
+		FP.T["HistoryJumpedToSnapshot"],
+		FP.UndoBufferPos+1,
+		len(FP.UndoBuffer),
+		Reset,
+	))
+
+	populateProfilesPage()
+	getTransactionsTable()
+	FP.TransactionsTable.Select(FP.SelectedProfile.SelectedRow, FP.SelectedProfile.SelectedColumn)
+
+	getHistoryTable()
+
+	FP.App.SetFocus(FP.HistoryTable)
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// This file contains the transaction templates that can be picked from when
+// adding a new transaction, so that common schedules don't have to be pieced
+// together by hand.
+
+// TXTemplate is a preset for a new transaction. Apply configures a new
+// transaction, which was created at now, according to the template.
+type TXTemplate struct {
+	// translation key for the template's button in the picker
+	Button string
+	Apply  func(tx *TX, now time.Time)
+}
+
+// clearTXEnds removes the end date that new transactions are given by
+// default, since the schedules created by templates are meant to be ongoing.
+func clearTXEnds(tx *TX) {
+	tx.EndsDay = 0
+	tx.EndsMonth = 0
+	tx.EndsYear = 0
+}
+
+// getTXTemplates returns the available transaction templates, in the order
+// that they are shown in the picker. The first template is a blank
+// transaction, which is the same as adding a transaction without a template.
+func getTXTemplates() []TXTemplate {
+	return []TXTemplate{
+		{
+			Button: "PromptTXTemplateButtonBlank",
+			Apply:  func(_ *TX, _ time.Time) {},
+		},
+		{
+			// paid on the 1st and the 15th of every month
+			Button: "PromptTXTemplateButtonSemiMonthly",
+			Apply: func(tx *TX, now time.Time) {
+				tx.Name = FP.T["TXTemplateNameSemiMonthly"]
+				tx.RRule = "FREQ=MONTHLY;BYMONTHDAY=1,15"
+				tx.StartsDay = 1
+				clearTXEnds(tx)
+			},
+		},
+		{
+			// paid every other friday, starting with the next one
+			Button: "PromptTXTemplateButtonBiweekly",
+			Apply: func(tx *TX, now time.Time) {
+				friday := now.AddDate(0, 0, (int(time.Friday)-int(now.Weekday())+7)%7)
+
+				tx.Name = FP.T["TXTemplateNameBiweekly"]
+				tx.RRule = "FREQ=WEEKLY;INTERVAL=2;BYDAY=FR"
+				tx.StartsDay = friday.Day()
+				tx.StartsMonth = int(friday.Month())
+				tx.StartsYear = friday.Year()
+				clearTXEnds(tx)
+			},
+		},
+		{
+			// every three months, starting today
+			Button: "PromptTXTemplateButtonQuarterly",
+			Apply: func(tx *TX, _ time.Time) {
+				tx.Name = FP.T["TXTemplateNameQuarterly"]
+				tx.Frequency = MONTHLY
+				tx.Interval = 3
+				clearTXEnds(tx)
+			},
+		},
+		{
+			// the last weekday of every month, which is moved back to the
+			// previous business day if it is a holiday
+			Button: "PromptTXTemplateButtonLastBusinessDay",
+			Apply: func(tx *TX, _ time.Time) {
+				tx.Name = FP.T["TXTemplateNameLastBusinessDay"]
+				tx.RRule = "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1"
+				tx.Roll = RollPrevious
+				tx.StartsDay = 1
+				clearTXEnds(tx)
+			},
+		},
+	}
+}
+
+// promptTXTemplate lets the user pick a template for a new transaction. Once
+// picked, the new transaction is passed to add. Nothing is added if the user
+// cancels.
+func promptTXTemplate(add func(tx TX)) {
+	templates := getTXTemplates()
+	buttons := make([]string, 0, len(templates)+1)
+
+	for i := range templates {
+		buttons = append(buttons, FP.T[templates[i].Button])
+	}
+
+	buttons = append(buttons, FP.T["PromptTXTemplateButtonCancel"])
+
+	FP.PromptBox.ClearButtons().AddButtons(buttons).
+		SetText(FP.T["PromptTXTemplateText"]).
+		SetDoneFunc(
+			func(buttonIndex int, _ /* buttonLabel */ string) {
+				FP.Pages.SwitchToPage(PageProfiles)
+				FP.App.SetFocus(FP.TransactionsTable)
+
+				if buttonIndex < 0 || buttonIndex >= len(templates) {
+					return
+				}
+
+				now := time.Now()
+				tx := getNewTX(now)
+				templates[buttonIndex].Apply(&tx, now)
+
+				add(tx)
+			},
+		).SetBackgroundColor(tcell.ColorDimGray).
+		SetTextColor(tcell.ColorWhite)
+
+	FP.Pages.SwitchToPage(PagePrompt)
+	FP.PromptBox.SetFocus(0)
+	FP.App.SetFocus(FP.PromptBox)
+}
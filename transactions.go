@@ -2,9 +2,11 @@ package main
 
 import (
 	"fmt"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	lib "github.com/charles-m-knox/finance-planner-lib"
 
@@ -29,6 +31,11 @@ func resetTransactionsInputFieldAutocomplete() {
 // or some other event demanding focus elsewhere, this function should be
 // executed.
 func deactivateTransactionsInputField() {
+	// any in-progress search is over, and the changed func must be removed
+	// before the text is cleared below
+	FP.TransactionsSearch.Active = false
+	FP.TransactionsInputField.SetChangedFunc(nil)
+
 	FP.TransactionsInputField.SetFieldBackgroundColor(
 		tcell.ColorNames[FP.Colors["TransactionsInputFieldBlurredBackground"]],
 	)
@@ -60,6 +67,9 @@ func activateTransactionsInputField(msg, value string) {
 // resetTransactionsInputFieldAutocomplete cannot be called without risking
 // an infinite loop, so this function does not call it.
 func activateTransactionsInputFieldNoAutocompleteReset(msg, value string) {
+	FP.TransactionsSearch.Active = false
+	FP.TransactionsInputField.SetChangedFunc(nil)
+
 	FP.TransactionsInputField.SetFieldBackgroundColor(
 		tcell.ColorNames[FP.Colors["TransactionsInputFieldFocusedBackground"]],
 	)
@@ -105,12 +115,12 @@ func getWeekdaysMap() map[string]int {
 }
 
 type (
-	TxSortFunc        func(ti, tj lib.TX) bool
+	TxSortFunc        func(ti, tj TX) bool
 	TxSortChooserFunc func(bool) TxSortFunc
 )
 
 func sortWeekday(day int, asc bool) TxSortFunc {
-	return func(ti, tj lib.TX) bool {
+	return func(ti, tj TX) bool {
 		tiw := ti.Weekdays[day]
 		tjw := tj.Weekdays[day]
 
@@ -133,7 +143,7 @@ func sortWeekday(day int, asc bool) TxSortFunc {
 // numeric sort functions
 
 func sortAmount(asc bool) TxSortFunc {
-	return func(ti, tj lib.TX) bool {
+	return func(ti, tj TX) bool {
 		if asc {
 			if ti.Amount == tj.Amount {
 				return ti.ID > tj.ID
@@ -150,26 +160,49 @@ func sortAmount(asc bool) TxSortFunc {
 	}
 }
 
+// Frequencies are sorted from most to least frequent rather than
+// alphabetically. Unknown frequencies are sorted last.
+var frequencyOrder = map[string]int{
+	DAILY:        1,
+	BUSINESSDAYS: 2,
+	WEEKLY:       3,
+	MONTHLY:      4,
+	YEARLY:       5,
+}
+
+// getFrequencyOrder returns the sort position of a frequency.
+func getFrequencyOrder(frequency string) int {
+	o, ok := frequencyOrder[frequency]
+	if !ok {
+		return len(frequencyOrder) + 1
+	}
+
+	return o
+}
+
 func sortFrequency(asc bool) TxSortFunc {
-	return func(ti, tj lib.TX) bool {
+	return func(ti, tj TX) bool {
+		oi := getFrequencyOrder(ti.Frequency)
+		oj := getFrequencyOrder(tj.Frequency)
+
 		if asc {
-			if ti.Frequency == tj.Frequency {
+			if oi == oj {
 				return ti.ID > tj.ID
 			}
 
-			return ti.Frequency > tj.Frequency
+			return oi > oj
 		}
 
-		if ti.Frequency == tj.Frequency {
+		if oi == oj {
 			return ti.ID < tj.ID
 		}
 
-		return ti.Frequency < tj.Frequency
+		return oi < oj
 	}
 }
 
 func sortInterval(asc bool) TxSortFunc {
-	return func(ti, tj lib.TX) bool {
+	return func(ti, tj TX) bool {
 		if asc {
 			if ti.Interval == tj.Interval {
 				return ti.ID > tj.ID
@@ -186,10 +219,106 @@ func sortInterval(asc bool) TxSortFunc {
 	}
 }
 
+func sortOccurrences(asc bool) TxSortFunc {
+	return func(ti, tj TX) bool {
+		if asc {
+			if ti.Occurrences == tj.Occurrences {
+				return ti.ID > tj.ID
+			}
+
+			return ti.Occurrences > tj.Occurrences
+		}
+
+		if ti.Occurrences == tj.Occurrences {
+			return ti.ID < tj.ID
+		}
+
+		return ti.Occurrences < tj.Occurrences
+	}
+}
+
 // string sort functions
 
+// Transactions without a roll convention are sorted as RollNone.
+func sortRoll(asc bool) TxSortFunc {
+	return func(ti, tj TX) bool {
+		ri := getRoll(ti.Roll)
+		rj := getRoll(tj.Roll)
+
+		if asc {
+			if ri == rj {
+				return ti.ID > tj.ID
+			}
+
+			return ri > rj
+		}
+
+		if ri == rj {
+			return ti.ID < tj.ID
+		}
+
+		return ri < rj
+	}
+}
+
+func sortRRule(asc bool) TxSortFunc {
+	return func(ti, tj TX) bool {
+		if asc {
+			if ti.RRule == tj.RRule {
+				return ti.ID > tj.ID
+			}
+
+			return ti.RRule > tj.RRule
+		}
+
+		if ti.RRule == tj.RRule {
+			return ti.ID < tj.ID
+		}
+
+		return ti.RRule < tj.RRule
+	}
+}
+
+// Transactions without an account belong to the main account, which is
+// sorted first.
+func sortAccount(asc bool) TxSortFunc {
+	return func(ti, tj TX) bool {
+		if asc {
+			if ti.Account == tj.Account {
+				return ti.ID > tj.ID
+			}
+
+			return ti.Account > tj.Account
+		}
+
+		if ti.Account == tj.Account {
+			return ti.ID < tj.ID
+		}
+
+		return ti.Account < tj.Account
+	}
+}
+
+func sortTransferTo(asc bool) TxSortFunc {
+	return func(ti, tj TX) bool {
+		if asc {
+			if ti.TransferTo == tj.TransferTo {
+				return ti.ID > tj.ID
+			}
+
+			return ti.TransferTo > tj.TransferTo
+		}
+
+		if ti.TransferTo == tj.TransferTo {
+			return ti.ID < tj.ID
+		}
+
+		return ti.TransferTo < tj.TransferTo
+	}
+}
+
 func sortNote(asc bool) TxSortFunc {
-	return func(ti, tj lib.TX) bool {
+	return func(ti, tj TX) bool {
 		til := strings.ToLower(ti.Note)
 		tjl := strings.ToLower(tj.Note)
 
@@ -210,7 +339,7 @@ func sortNote(asc bool) TxSortFunc {
 }
 
 func sortName(asc bool) TxSortFunc {
-	return func(ti, tj lib.TX) bool {
+	return func(ti, tj TX) bool {
 		til := strings.ToLower(ti.Name)
 		tjl := strings.ToLower(tj.Name)
 
@@ -231,7 +360,7 @@ func sortName(asc bool) TxSortFunc {
 }
 
 // func sortID(asc bool) TxSortFunc {
-// 	return func(ti, tj lib.TX) bool {
+// 	return func(ti, tj TX) bool {
 // 		til := strings.ToLower(ti.ID)
 // 		tjl := strings.ToLower(tj.ID)
 
@@ -254,7 +383,7 @@ func sortName(asc bool) TxSortFunc {
 // string-typed date sorting functions
 
 func sortStarts(asc bool) TxSortFunc {
-	return func(ti, tj lib.TX) bool {
+	return func(ti, tj TX) bool {
 		tis := ti.GetStartDateString()
 		tjs := tj.GetStartDateString()
 
@@ -275,7 +404,7 @@ func sortStarts(asc bool) TxSortFunc {
 }
 
 func sortEnds(asc bool) TxSortFunc {
-	return func(ti, tj lib.TX) bool {
+	return func(ti, tj TX) bool {
 		tis := ti.GetEndsDateString()
 		tjs := tj.GetEndsDateString()
 
@@ -299,7 +428,7 @@ func sortEnds(asc bool) TxSortFunc {
 
 // // TODO: validate that this works as expected.
 // func sortCreatedAt(asc bool) TxSortFunc {
-// 	return func(ti, tj lib.TX) bool {
+// 	return func(ti, tj TX) bool {
 // 		if asc {
 // 			return ti.CreatedAt.After(tj.CreatedAt)
 // 		}
@@ -310,7 +439,7 @@ func sortEnds(asc bool) TxSortFunc {
 
 // // TODO: validate that this works as expected.
 // func sortUpdatedAt(asc bool) TxSortFunc {
-// 	return func(ti, tj lib.TX) bool {
+// 	return func(ti, tj TX) bool {
 // 		if asc {
 // 			return ti.UpdatedAt.After(tj.UpdatedAt)
 // 		}
@@ -322,7 +451,7 @@ func sortEnds(asc bool) TxSortFunc {
 // boolean sort functions
 
 func sortActive(asc bool) TxSortFunc {
-	return func(ti, tj lib.TX) bool {
+	return func(ti, tj TX) bool {
 		if asc {
 			if ti.Active == tj.Active {
 				return ti.ID > tj.ID
@@ -359,21 +488,26 @@ func getTransactionsColumns() map[string]TransactionsColumn {
 	su := func(b bool) TxSortFunc { return sortWeekday(rrule.SU.Day(), b) }
 
 	return map[string]TransactionsColumn{
-		FP.T["TransactionsColumnAmount"]:    {SortFunc: sortAmount},
-		FP.T["TransactionsColumnActive"]:    {SortFunc: sortActive},
-		FP.T["TransactionsColumnName"]:      {SortFunc: sortName},
-		FP.T["TransactionsColumnFrequency"]: {SortFunc: sortFrequency},
-		FP.T["TransactionsColumnInterval"]:  {SortFunc: sortInterval},
-		FP.T["TransactionsColumnMonday"]:    {SortFunc: mo},
-		FP.T["TransactionsColumnTuesday"]:   {SortFunc: tu},
-		FP.T["TransactionsColumnWednesday"]: {SortFunc: we},
-		FP.T["TransactionsColumnThursday"]:  {SortFunc: th},
-		FP.T["TransactionsColumnFriday"]:    {SortFunc: fr},
-		FP.T["TransactionsColumnSaturday"]:  {SortFunc: sa},
-		FP.T["TransactionsColumnSunday"]:    {SortFunc: su},
-		FP.T["TransactionsColumnStarts"]:    {SortFunc: sortStarts},
-		FP.T["TransactionsColumnEnds"]:      {SortFunc: sortEnds},
-		FP.T["TransactionsColumnNote"]:      {SortFunc: sortNote},
+		FP.T["TransactionsColumnAmount"]:      {SortFunc: sortAmount},
+		FP.T["TransactionsColumnActive"]:      {SortFunc: sortActive},
+		FP.T["TransactionsColumnName"]:        {SortFunc: sortName},
+		FP.T["TransactionsColumnFrequency"]:   {SortFunc: sortFrequency},
+		FP.T["TransactionsColumnInterval"]:    {SortFunc: sortInterval},
+		FP.T["TransactionsColumnMonday"]:      {SortFunc: mo},
+		FP.T["TransactionsColumnTuesday"]:     {SortFunc: tu},
+		FP.T["TransactionsColumnWednesday"]:   {SortFunc: we},
+		FP.T["TransactionsColumnThursday"]:    {SortFunc: th},
+		FP.T["TransactionsColumnFriday"]:      {SortFunc: fr},
+		FP.T["TransactionsColumnSaturday"]:    {SortFunc: sa},
+		FP.T["TransactionsColumnSunday"]:      {SortFunc: su},
+		FP.T["TransactionsColumnStarts"]:      {SortFunc: sortStarts},
+		FP.T["TransactionsColumnEnds"]:        {SortFunc: sortEnds},
+		FP.T["TransactionsColumnOccurrences"]: {SortFunc: sortOccurrences},
+		FP.T["TransactionsColumnRoll"]:        {SortFunc: sortRoll},
+		FP.T["TransactionsColumnRRule"]:       {SortFunc: sortRRule},
+		FP.T["TransactionsColumnAccount"]:     {SortFunc: sortAccount},
+		FP.T["TransactionsColumnTransferTo"]:  {SortFunc: sortTransferTo},
+		FP.T["TransactionsColumnNote"]:        {SortFunc: sortNote},
 	}
 }
 
@@ -455,13 +589,34 @@ func getTransactionsTableHeaders() []TableCell {
 		{Text: FP.T["TransactionsColumnSunday"], Color: FP.Colors["TransactionsColumnSunday"]},
 		{Text: FP.T["TransactionsColumnStarts"], Color: FP.Colors["TransactionsColumnStarts"]},
 		{Text: FP.T["TransactionsColumnEnds"], Color: FP.Colors["TransactionsColumnEnds"]},
+		{Text: FP.T["TransactionsColumnOccurrences"], Color: FP.Colors["TransactionsColumnOccurrences"]},
+		{Text: FP.T["TransactionsColumnRoll"], Color: FP.Colors["TransactionsColumnRoll"]},
+		{Text: FP.T["TransactionsColumnRRule"], Color: FP.Colors["TransactionsColumnRRule"]},
+		{Text: FP.T["TransactionsColumnAccount"], Color: FP.Colors["TransactionsColumnAccount"]},
+		{Text: FP.T["TransactionsColumnTransferTo"], Color: FP.Colors["TransactionsColumnTransferTo"]},
 		{Text: FP.T["TransactionsColumnNote"], Color: FP.Colors["TransactionsColumnNote"], Expand: 1},
 	}
 }
 
+// getOccurrencesText returns the text for the occurrences column, which
+// includes the date of the final occurrence, if the number of occurrences is
+// limited.
+func getOccurrencesText(tx *TX, holidays HolidayCalendar) string {
+	if tx.Occurrences < 1 {
+		return ""
+	}
+
+	final, ok := getFinalOccurrence(tx, holidays)
+	if !ok {
+		return strconv.Itoa(tx.Occurrences)
+	}
+
+	return fmt.Sprintf("%v (%v %v)", tx.Occurrences, FP.T["TransactionsOccurrencesFinal"], final.Format(time.DateOnly))
+}
+
 // Returns a list, representing the ordered columns to be shown in
 // the transactions table, alongside their configured colors.
-func getTransactionsTableCell(tx lib.TX) []TableCell {
+func getTransactionsTableCell(tx TX, holidays HolidayCalendar) []TableCell {
 	cAmount := FP.Colors["TransactionsColumnAmount"]
 	cActive := FP.Colors["TransactionsColumnActive"]
 	cName := FP.Colors["TransactionsColumnName"]
@@ -476,10 +631,24 @@ func getTransactionsTableCell(tx lib.TX) []TableCell {
 	cSunday := FP.Colors["TransactionsColumnSunday"]
 	cStarts := FP.Colors["TransactionsColumnStarts"]
 	cEnds := FP.Colors["TransactionsColumnEnds"]
+	cOccurrences := FP.Colors["TransactionsColumnOccurrences"]
+	cRoll := FP.Colors["TransactionsColumnRoll"]
+	cRRule := FP.Colors["TransactionsColumnRRule"]
+	cAccount := FP.Colors["TransactionsColumnAccount"]
+	cTransferTo := FP.Colors["TransactionsColumnTransferTo"]
 	cNote := FP.Colors["TransactionsColumnNote"]
 
 	active := FP.T["CheckedGlyph"]
 
+	amount := lib.FormatAsCurrency(tx.Amount)
+	if hasAmountSchedule(&tx) {
+		amount = fmt.Sprintf("%v%v", amount, FP.T["AmountScheduleGlyph"])
+	}
+
+	if hasUncertainty(&tx) {
+		amount = fmt.Sprintf("%v%v", amount, FP.T["UncertaintyGlyph"])
+	}
+
 	w := tx.GetWeekdaysCheckedMap(FP.T["CheckedGlyph"], FP.T["UncheckedGlyph"])
 
 	if !tx.Active {
@@ -498,6 +667,11 @@ func getTransactionsTableCell(tx lib.TX) []TableCell {
 		cSunday = FP.Colors["TransactionsInactive"]
 		cStarts = FP.Colors["TransactionsInactive"]
 		cEnds = FP.Colors["TransactionsInactive"]
+		cOccurrences = FP.Colors["TransactionsInactive"]
+		cRoll = FP.Colors["TransactionsInactive"]
+		cRRule = FP.Colors["TransactionsInactive"]
+		cAccount = FP.Colors["TransactionsInactive"]
+		cTransferTo = FP.Colors["TransactionsInactive"]
 		cNote = FP.Colors["TransactionsInactive"]
 	} else { //nolint:gocritic // <-- intentionally structured like this
 		if tx.Amount >= 0 {
@@ -506,7 +680,7 @@ func getTransactionsTableCell(tx lib.TX) []TableCell {
 	}
 
 	cells := []TableCell{
-		{Text: lib.FormatAsCurrency(tx.Amount), Color: cAmount, Align: tview.AlignCenter},
+		{Text: amount, Color: cAmount, Align: tview.AlignCenter},
 		{Text: active, Color: cActive, Align: tview.AlignCenter},
 		{Text: tx.Name, Color: cName, Expand: 1, Align: tview.AlignLeft},
 		{Text: tx.Frequency, Color: cFrequency, Align: tview.AlignCenter},
@@ -520,6 +694,11 @@ func getTransactionsTableCell(tx lib.TX) []TableCell {
 		{Text: w[rrule.SU.Day()], Color: cSunday, Align: tview.AlignCenter},
 		{Text: tx.GetStartDateString(), Color: cStarts, Align: tview.AlignCenter},
 		{Text: tx.GetEndsDateString(), Color: cEnds, Align: tview.AlignCenter},
+		{Text: getOccurrencesText(&tx, holidays), Color: cOccurrences, Align: tview.AlignCenter},
+		{Text: tx.Roll, Color: cRoll, Align: tview.AlignCenter},
+		{Text: tview.Escape(strings.ReplaceAll(tx.RRule, "\n", " ")), Color: cRRule, Align: tview.AlignLeft},
+		{Text: tview.Escape(tx.Account), Color: cAccount, Align: tview.AlignCenter},
+		{Text: tview.Escape(tx.TransferTo), Color: cTransferTo, Align: tview.AlignCenter},
 		{Text: tx.Note, Color: cNote, Expand: 1, Align: tview.AlignLeft},
 	}
 
@@ -551,8 +730,8 @@ func setTransactionsTableHeaders(th []TableCell, currentSort, sortGlyph string)
 
 // Constructs and sets the columns for the i'th row in the transactions table.
 // Unsafe to run repeatedly and does not clear any existing fields/data.
-func setTransactionsTableCellsForTransaction(i int, tx lib.TX, isLastSelection bool) {
-	td := getTransactionsTableCell(tx)
+func setTransactionsTableCellsForTransaction(i int, tx TX, holidays HolidayCalendar, isLastSelection, isSearchMatch bool) {
+	td := getTransactionsTableCell(tx, holidays)
 
 	bg := tcell.ColorReset
 
@@ -565,6 +744,8 @@ func setTransactionsTableCellsForTransaction(i int, tx lib.TX, isLastSelection b
 		bg = tcell.GetColor(FP.Colors["TransactionsRowLastSelectedColor"])
 	} else if tx.Selected {
 		bg = tcell.GetColor(FP.Colors["TransactionsRowSelectedColor"])
+	} else if isSearchMatch {
+		bg = tcell.GetColor(FP.Colors["TransactionsRowSearchMatchColor"])
 	}
 
 	for j := range td {
@@ -587,6 +768,10 @@ func setTransactionsTableCellsForTransaction(i int, tx lib.TX, isLastSelection b
 func getTransactionsTable() {
 	FP.TransactionsTable.Clear()
 
+	// the goals belong to the same profile, so they are refreshed alongside
+	// its transactions
+	getGoalsTable()
+
 	currentSort, sortGlyph := getSort(FP.SortTX)
 
 	FP.TransactionsTableHeaders = getTransactionsTableHeaders()
@@ -604,8 +789,20 @@ func getTransactionsTable() {
 
 	sortTX(FP.TransactionsSortMap)
 
+	updateTransactionsSearchRows()
+
+	// the holidays are only needed for the final occurrences, which are
+	// shown without them if they are invalid
+	holidays, _ := getHolidays(&FP.Config)
+
 	for i := range FP.SelectedProfile.TX {
-		setTransactionsTableCellsForTransaction(i+1, FP.SelectedProfile.TX[i], FP.LastSelection == i)
+		setTransactionsTableCellsForTransaction(
+			i+1,
+			FP.SelectedProfile.TX[i],
+			holidays,
+			FP.LastSelection == i,
+			txMatchesSearch(FP.SelectedProfile.TX[i], FP.TransactionsSearch.Query),
+		)
 	}
 
 	FP.TransactionsTable.SetSelectedFunc(transactionsTableSelectedFunc)
@@ -765,22 +962,17 @@ func txChangeDate(i int, start bool) {
 	FP.TransactionsInputField.SetDoneFunc(fnc(Y, yearFunc))
 }
 
-// TODO: translate these.
 // TODO: map colors, if any are used.
 func txChangeFrequency(i int) {
-	activateTransactionsInputField("weekly|monthly|yearly:", FP.SelectedProfile.TX[i].Frequency)
+	activateTransactionsInputField(fmt.Sprintf("%v:", FP.T["TransactionsInputFieldFrequencyPromptLabel"]), FP.SelectedProfile.TX[i].Frequency)
 
 	saveFunc := func(newValue string) {
 		validatedFrequency := strings.TrimSpace(strings.ToUpper(newValue))
 		switch validatedFrequency {
-		case WEEKLY:
-			fallthrough
-		case MONTHLY:
-			fallthrough
-		case YEARLY:
+		case DAILY, BUSINESSDAYS, WEEKLY, MONTHLY, YEARLY:
 			break
 		default:
-			FP.TransactionsInputField.SetLabel("invalid value - can only be weekly, monthly, or yearly:")
+			FP.TransactionsInputField.SetLabel(fmt.Sprintf("%v:", FP.T["TransactionsInputFieldInvalidFrequencyLabel"]))
 
 			return
 		}
@@ -802,6 +994,8 @@ func txChangeFrequency(i int) {
 			MONTHLY,
 			YEARLY,
 			WEEKLY,
+			DAILY,
+			BUSINESSDAYS,
 		})
 	})
 
@@ -877,14 +1071,54 @@ func txSetAmount(i int, amt string) bool {
 func txChangeAmount(i int) {
 	FP.TransactionsInputField.SetDoneFunc(txChangeDoneFunc(i, txSetAmount))
 
-	renderedAmount := lib.FormatAsCurrency(FP.SelectedProfile.TX[i].Amount)
-	if FP.SelectedProfile.TX[i].Amount >= 0 {
-		renderedAmount = fmt.Sprintf("+%v", renderedAmount)
+	activateTransactionsInputField(
+		fmt.Sprintf("%v:", FP.T["TransactionsInputFieldEditAmountLabel"]),
+		formatEditableAmount(FP.SelectedProfile.TX[i].Amount),
+	)
+}
+
+// Updates the amount schedule of all selected transactions as well as the
+// current one. See parseAmountSchedule for the format of the schedule.
+func txSetAmountSchedule(i int, schedule string) bool {
+	changes, escalation, uncertainty, err := parseAmountSchedule(schedule)
+	if err != nil {
+		activateTransactionsInputFieldNoAutocompleteReset(
+			fmt.Sprintf("%v:", tview.Escape(err.Error())),
+			schedule,
+		)
+
+		return false
+	}
+
+	// escalation compounds from the start date
+	for j := range FP.SelectedProfile.TX {
+		if (FP.SelectedProfile.TX[j].Selected || j == i) && escalation != 0 && !hasTXStartDate(&FP.SelectedProfile.TX[j]) {
+			activateTransactionsInputFieldNoAutocompleteReset(
+				fmt.Sprintf("%v:", FP.T["AmountScheduleEscalationNoStartDate"]),
+				schedule,
+			)
+
+			return false
+		}
 	}
 
+	for j := range FP.SelectedProfile.TX {
+		if FP.SelectedProfile.TX[j].Selected || j == i {
+			FP.SelectedProfile.TX[j].AmountChanges = slices.Clone(changes)
+			FP.SelectedProfile.TX[j].Escalation = escalation
+			FP.SelectedProfile.TX[j].Uncertainty = uncertainty
+		}
+	}
+
+	return true
+}
+
+func txChangeAmountSchedule(i int) {
+	FP.TransactionsInputField.SetDoneFunc(txChangeDoneFunc(i, txSetAmountSchedule))
+
 	activateTransactionsInputField(
-		fmt.Sprintf("%v:", FP.T["TransactionsInputFieldEditAmountLabel"]),
-		renderedAmount,
+		fmt.Sprintf("%v:", FP.T["TransactionsInputFieldEditAmountScheduleLabel"]),
+		formatAmountSchedule(&FP.SelectedProfile.TX[i]),
 	)
 }
 
@@ -954,6 +1188,188 @@ func txChangeNote(i int) {
 	)
 }
 
+// TODO: map colors, if any are used.
+func txChangeRoll(i int) {
+	activateTransactionsInputField(fmt.Sprintf("%v:", FP.T["TransactionsInputFieldRollPromptLabel"]), getRoll(FP.SelectedProfile.TX[i].Roll))
+
+	saveFunc := func(newValue string) {
+		validatedRoll := strings.TrimSpace(strings.ToUpper(newValue))
+		switch validatedRoll {
+		case RollNone:
+			validatedRoll = ""
+		case RollPrevious, RollNext:
+			break
+		default:
+			FP.TransactionsInputField.SetLabel(fmt.Sprintf("%v:", FP.T["TransactionsInputFieldInvalidRollLabel"]))
+
+			return
+		}
+
+		// update all selected values as well as the current one
+		for j := range FP.SelectedProfile.TX {
+			if FP.SelectedProfile.TX[j].Selected || j == i {
+				FP.SelectedProfile.TX[j].Roll = validatedRoll
+			}
+		}
+
+		modified()
+	}
+
+	FP.TransactionsInputField.SetAutocompleteFunc(func(currentText string) []string {
+		return fuzzy.Find(strings.TrimSpace(strings.ToUpper(currentText)), []string{
+			RollNone,
+			RollPrevious,
+			RollNext,
+		})
+	})
+
+	FP.TransactionsInputField.SetAutocompletedFunc(func(text string, _ /* index */, _ /* source */ int) bool {
+		saveFunc(text)
+		deactivateTransactionsInputField()
+
+		return true
+	})
+
+	FP.TransactionsInputField.SetDoneFunc(func(key tcell.Key) {
+		switch key {
+		case tcell.KeyEscape:
+			break
+		default:
+			saveFunc(FP.TransactionsInputField.GetText())
+		}
+
+		deactivateTransactionsInputField()
+	})
+}
+
+// txChangeAccount opens the transactions input field for changing the
+// account of the i'th transaction, or, if transfer is true, the account that
+// it transfers to. Only the profile's accounts are accepted, and an empty
+// value (or the main account's name) means the main account, or no transfer.
+func txChangeAccount(i int, transfer bool) {
+	label := FP.T["TransactionsInputFieldAccountPromptLabel"]
+	value := FP.SelectedProfile.TX[i].Account
+
+	if transfer {
+		label = FP.T["TransactionsInputFieldTransferToPromptLabel"]
+		value = FP.SelectedProfile.TX[i].TransferTo
+	}
+
+	activateTransactionsInputField(fmt.Sprintf("%v:", label), value)
+
+	saveFunc := func(newValue string) bool {
+		account := strings.TrimSpace(newValue)
+		if account == FP.T["AccountMainName"] {
+			account = ""
+		}
+
+		if validateAccount(FP.SelectedProfile, account) != nil {
+			FP.TransactionsInputField.SetLabel(fmt.Sprintf("%v:", FP.T["TransactionsInputFieldInvalidAccountLabel"]))
+
+			return false
+		}
+
+		// update all selected values as well as the current one
+		for j := range FP.SelectedProfile.TX {
+			if FP.SelectedProfile.TX[j].Selected || j == i {
+				if transfer {
+					FP.SelectedProfile.TX[j].TransferTo = account
+				} else {
+					FP.SelectedProfile.TX[j].Account = account
+				}
+			}
+		}
+
+		modified()
+
+		return true
+	}
+
+	FP.TransactionsInputField.SetAutocompleteFunc(func(currentText string) []string {
+		names := []string{FP.T["AccountMainName"]}
+		for _, account := range FP.SelectedProfile.Accounts {
+			names = append(names, account.Name)
+		}
+
+		return fuzzy.Find(strings.TrimSpace(currentText), names)
+	})
+
+	FP.TransactionsInputField.SetAutocompletedFunc(func(text string, _ /* index */, _ /* source */ int) bool {
+		if saveFunc(text) {
+			deactivateTransactionsInputField()
+		}
+
+		return true
+	})
+
+	FP.TransactionsInputField.SetDoneFunc(func(key tcell.Key) {
+		if key != tcell.KeyEscape && !saveFunc(FP.TransactionsInputField.GetText()) {
+			return
+		}
+
+		deactivateTransactionsInputField()
+	})
+}
+
+func txSetRRule(i int, rule string) {
+	for j := range FP.SelectedProfile.TX {
+		if FP.SelectedProfile.TX[j].Selected || j == i {
+			FP.SelectedProfile.TX[j].RRule = rule
+		}
+	}
+}
+
+func txChangeRRule(i int) {
+	txEditRRule(i, FP.SelectedProfile.TX[i].RRule)
+}
+
+// txEditRRule opens the transactions input field for editing the rrule of the
+// i'th transaction, starting with the given value. A valid rrule is not
+// applied right away; instead, a preview of its next occurrences is shown
+// first. An empty value clears the rrule.
+func txEditRRule(i int, value string) {
+	FP.TransactionsInputField.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEscape {
+			deactivateTransactionsInputField()
+
+			return
+		}
+
+		rule := strings.TrimSpace(FP.TransactionsInputField.GetText())
+		if rule == "" {
+			txSetRRule(i, rule)
+			modified()
+			deactivateTransactionsInputField()
+
+			return
+		}
+
+		tx := FP.SelectedProfile.TX[i]
+		tx.RRule = rule
+
+		now := time.Now()
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+		set, err := getTXRRule(&tx, today)
+		if err != nil {
+			activateTransactionsInputFieldNoAutocompleteReset(
+				fmt.Sprintf("%v:", tview.Escape(err.Error())),
+				rule,
+			)
+
+			return // don't drop focus - the user entered invalid input
+		}
+
+		deactivateTransactionsInputField()
+		promptRRulePreview(i, rule, getRRuleOccurrences(set, today, RRulePreviewCount))
+	})
+
+	activateTransactionsInputField(
+		fmt.Sprintf("%v:", FP.T["TransactionsInputFieldEditRRuleLabel"]),
+		value,
+	)
+}
+
 func txSetInterval(i int, interval string) bool {
 	d, err := strconv.ParseInt(interval, 10, 64)
 	if err != nil || d < 0 {
@@ -978,6 +1394,53 @@ func txSetInterval(i int, interval string) bool {
 	return true
 }
 
+func txSetOccurrences(i int, occurrences string) bool {
+	occurrences = strings.TrimSpace(occurrences)
+	if occurrences == "" {
+		occurrences = "0"
+	}
+
+	d, err := strconv.ParseInt(occurrences, 10, 64)
+	if err != nil || d < 0 {
+		activateTransactionsInputFieldNoAutocompleteReset(
+			fmt.Sprintf("%v:", FP.T["TransactionsInputFieldInvalidOccurrencesGivenLabel"]),
+			strconv.Itoa(FP.SelectedProfile.TX[i].Occurrences),
+		)
+
+		return false
+	}
+
+	// occurrences are counted from the start date, unless the rrule has one
+	for j := range FP.SelectedProfile.TX {
+		tx := &FP.SelectedProfile.TX[j]
+		if (tx.Selected || j == i) && d > 0 && !hasTXStartDate(tx) && strings.TrimSpace(tx.RRule) == "" {
+			activateTransactionsInputFieldNoAutocompleteReset(
+				fmt.Sprintf("%v:", FP.T["OccurrencesNoStartDate"]),
+				occurrences,
+			)
+
+			return false
+		}
+	}
+
+	for j := range FP.SelectedProfile.TX {
+		if FP.SelectedProfile.TX[j].Selected || j == i {
+			FP.SelectedProfile.TX[j].Occurrences = int(d)
+		}
+	}
+
+	return true
+}
+
+func txChangeOccurrences(i int) {
+	FP.TransactionsInputField.SetDoneFunc(txChangeDoneFunc(i, txSetOccurrences))
+
+	activateTransactionsInputField(
+		fmt.Sprintf("%v:", FP.T["TransactionsInputFieldEditOccurrencesLabel"]),
+		strconv.Itoa(FP.SelectedProfile.TX[i].Occurrences),
+	)
+}
+
 func txChangeInterval(i int) {
 	FP.TransactionsInputField.SetDoneFunc(txChangeDoneFunc(i, txSetInterval))
 
@@ -1046,6 +1509,16 @@ func transactionsTableSelectedFunc(row, column int) {
 		txChangeDate(i, true)
 	case FP.T["TransactionsColumnEnds"]:
 		txChangeDate(i, false)
+	case FP.T["TransactionsColumnOccurrences"]:
+		txChangeOccurrences(i)
+	case FP.T["TransactionsColumnRoll"]:
+		txChangeRoll(i)
+	case FP.T["TransactionsColumnRRule"]:
+		txChangeRRule(i)
+	case FP.T["TransactionsColumnAccount"]:
+		txChangeAccount(i, false)
+	case FP.T["TransactionsColumnTransferTo"]:
+		txChangeAccount(i, true)
 	case FP.T["TransactionsColumnNote"]:
 		txChangeNote(i)
 	default:
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"io"
 	"slices"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -30,6 +32,7 @@ func initializeUndo(b []byte, noGz bool) {
 	}
 
 	FP.UndoBufferPos = 0
+	FP.UndoBufferTimes = []time.Time{time.Now()}
 }
 
 // Sets the FP.SelectedProfile & config to the value specified by the current
@@ -72,6 +75,12 @@ func pushUndoBufferChangeToConfig() {
 			return
 		}
 	}
+
+	// the selected profile may not exist in this snapshot, such as when
+	// jumping to a snapshot from before it was created
+	if len(FP.Config.Profiles) > 0 {
+		FP.SelectedProfile = &(FP.Config.Profiles[0])
+	}
 }
 
 // Moves 1 step backward in the FP.UndoBuffer.
@@ -203,6 +212,13 @@ func modified() {
 	}
 
 	FP.SelectedProfile.Modified = true
+
+	// a loan's payments are derived from the loan
+	holidays, err := getHolidays(&FP.Config)
+	if err == nil {
+		updateLoans(FP.SelectedProfile, holidays)
+	}
+
 	cr, cc := FP.TransactionsTable.GetSelection()
 	FP.SelectedProfile.SelectedColumn = cc
 	FP.SelectedProfile.SelectedRow = cr
@@ -236,6 +252,8 @@ func modified() {
 			}
 		}
 
+		touchUpdatedTransactions(bo)
+
 		sbo := string(bo)
 		sb := string(b)
 
@@ -257,7 +275,8 @@ func modified() {
 	// if the FP.UndoBufferPos is not at the end of the FP.UndoBuffer, then all
 	// values after FP.UndoBufferPos need to be deleted
 	if FP.UndoBufferPos != len(FP.UndoBuffer)-1 {
-		FP.UndoBuffer = slices.Delete(FP.UndoBuffer, FP.UndoBufferPos, len(FP.UndoBuffer))
+		FP.UndoBuffer = slices.Delete(FP.UndoBuffer, FP.UndoBufferPos+1, len(FP.UndoBuffer))
+		FP.UndoBufferTimes = slices.Delete(FP.UndoBufferTimes, FP.UndoBufferPos+1, len(FP.UndoBufferTimes))
 	}
 
 	getTransactionsTable()
@@ -292,27 +311,145 @@ func modified() {
 	}
 
 	FP.UndoBuffer = append(FP.UndoBuffer, bgz)
+	FP.UndoBufferTimes = append(FP.UndoBufferTimes, time.Now())
 	FP.UndoBufferPos = len(FP.UndoBuffer) - 1
 
-	totalUndoBufferSize := 0
-	for i := range FP.UndoBuffer {
-		totalUndoBufferSize += len(FP.UndoBuffer[i])
-	}
-
-	// TODO: restrict the length of the buffer based on the configured max
+	evicted := trimUndoBuffer(FP.Config.UndoBufferMaxLength, FP.Config.UndoBufferMaxBytes)
 
 	pushUndoBufferChangeToConfig()
 	FP.ProfileStatusText.SetText(fmt.Sprintf(
-		"%v%v*%v[%v/%v %vkB]%v",
+		"%v%v*%v%v%v",
 		FP.Colors["ProfileStatusTextModifiedMarker"],
 		Reset,
 		FP.Colors["ProfileStatusTextPassive"],
-		// FP.FlagConfigFile,
-		FP.UndoBufferPos+1,
-		len(FP.UndoBuffer),
-		// float64(len(bgz)/1000),
-		// float64(len(b)/1000),
-		float64(totalUndoBufferSize/1000),
+		getUndoBufferUsage(evicted),
 		Reset,
 	))
+	if FP.Config.AutosaveOnChange {
+		autosave()
+	}
+}
+
+// touchUpdatedTransactions sets UpdatedAt to now for every transaction in the
+// selected profile that differs from its counterpart (by ID) in prev, which
+// is the serialized config from the current undo buffer position. Selecting
+// a transaction does not count as changing it. UpdatedAt is used as a hint
+// when merging configs.
+func touchUpdatedTransactions(prev []byte) {
+	c := Config{}
+
+	err := yaml.Unmarshal(prev, &c)
+	if err != nil {
+		return
+	}
+
+	var p *Profile
+
+	for i := range c.Profiles {
+		if c.Profiles[i].Name == FP.SelectedProfile.Name {
+			p = &(c.Profiles[i])
+
+			break
+		}
+	}
+
+	if p == nil {
+		return
+	}
+
+	old := indexTX(p.TX)
+	keys := getTXKeys(FP.SelectedProfile.TX)
+	now := time.Now()
+
+	for i := range FP.SelectedProfile.TX {
+		tx := &(FP.SelectedProfile.TX[i])
+
+		o, ok := old[keys[i]]
+		if !ok {
+			continue
+		}
+
+		a, b := *o, *tx
+		a.Selected, b.Selected = false, false
+		a.UpdatedAt, b.UpdatedAt = time.Time{}, time.Time{}
+
+		if !yamlEqual(a, b) {
+			tx.UpdatedAt = now
+		}
+	}
+}
+
+// getUndoBufferSize returns the combined size, in bytes, of every snapshot in
+// the undo buffer.
+func getUndoBufferSize() int {
+	total := 0
+	for i := range FP.UndoBuffer {
+		total += len(FP.UndoBuffer[i])
+	}
+
+	return total
+}
+
+// trimUndoBuffer drops the oldest snapshots from the undo buffer until it
+// satisfies both maxLength (a count of snapshots) and maxBytes. A value of 0
+// for either means that limit is not enforced. The snapshot at the current
+// FP.UndoBufferPos is never dropped, and FP.UndoBufferPos is shifted so that it
+// still points at the same snapshot afterwards.
+//
+// Returns the number of snapshots that were dropped.
+func trimUndoBuffer(maxLength, maxBytes int) int {
+	size := getUndoBufferSize()
+	evicted := 0
+
+	for FP.UndoBufferPos > 0 {
+		tooLong := maxLength > 0 && len(FP.UndoBuffer) > maxLength
+		tooBig := maxBytes > 0 && size > maxBytes
+
+		if !tooLong && !tooBig {
+			break
+		}
+
+		size -= len(FP.UndoBuffer[0])
+		FP.UndoBuffer = slices.Delete(FP.UndoBuffer, 0, 1)
+		FP.UndoBufferTimes = slices.Delete(FP.UndoBufferTimes, 0, 1)
+		FP.UndoBufferPos--
+		evicted++
+	}
+
+	FP.UndoBufferEvicted += evicted
+
+	return evicted
+}
+
+// getUndoBufferUsage renders the undo buffer's position, length and memory
+// usage for the status text, such as "[3/20 12kB/64kB]". The limits are only
+// shown when they are configured. If any snapshots were just dropped, that is
+// mentioned too.
+func getUndoBufferUsage(evicted int) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("[%v/%v", FP.UndoBufferPos+1, len(FP.UndoBuffer)))
+
+	if FP.Config.UndoBufferMaxLength > 0 {
+		sb.WriteString(fmt.Sprintf(" (%v %v)", FP.T["UndoBufferMax"], FP.Config.UndoBufferMaxLength))
+	}
+
+	sb.WriteString(fmt.Sprintf(" %vkB", getUndoBufferSize()/1000))
+
+	if FP.Config.UndoBufferMaxBytes > 0 {
+		sb.WriteString(fmt.Sprintf("/%vkB", FP.Config.UndoBufferMaxBytes/1000))
+	}
+
+	sb.WriteString("]")
+
+	if evicted > 0 {
+		sb.WriteString(fmt.Sprintf(" %v %v (%v %v)",
+			FP.T["UndoBufferEvicted"],
+			evicted,
+			FP.UndoBufferEvicted,
+			FP.T["UndoBufferEvictedTotal"],
+		))
+	}
+
+	return sb.String()
 }
@@ -0,0 +1,323 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"gopkg.in/yaml.v3"
+)
+
+// This file contains the logic for detecting changes that were made to the
+// config file outside of this application, such as in a text editor or by a
+// file syncing tool, so that they don't get silently overwritten.
+
+// How often the config file is checked for external changes.
+const ConfigWatchInterval = 2 * time.Second
+
+// ConfigFileState is what is known about the config file on disk as of the
+// last time it was loaded or saved.
+type ConfigFileState struct {
+	ModTime  time.Time
+	Size     int64
+	Checksum string
+}
+
+// recordConfigFileState remembers the current state of the config file on
+// disk, given the bytes that are known to be in it.
+func recordConfigFileState(b []byte) {
+	info, err := os.Stat(FP.FlagConfigFile)
+	if err != nil {
+		FP.ConfigFileState = ConfigFileState{}
+
+		return
+	}
+
+	FP.ConfigFileState = ConfigFileState{
+		ModTime:  info.ModTime(),
+		Size:     info.Size(),
+		Checksum: getChecksum(b),
+	}
+}
+
+// getExternalConfigChange checks whether the config file was changed on disk
+// since it was last loaded or saved. If it was, its new contents are
+// returned; otherwise, nil is returned. A missing config file is not
+// considered a change, since saving will simply create it again.
+func getExternalConfigChange() ([]byte, error) {
+	info, err := os.Stat(FP.FlagConfigFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to stat %v: %w", FP.FlagConfigFile, err)
+	}
+
+	s := FP.ConfigFileState
+	if info.ModTime().Equal(s.ModTime) && info.Size() == s.Size {
+		return nil, nil
+	}
+
+	b, err := os.ReadFile(FP.FlagConfigFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %v: %w", FP.FlagConfigFile, err)
+	}
+
+	// the file was touched, but its contents are the same
+	if getChecksum(b) == s.Checksum {
+		recordConfigFileState(b)
+
+		return nil, nil
+	}
+
+	return b, nil
+}
+
+// guardExternalConfigChange returns true if the config file was changed on
+// disk since it was last loaded or saved, in which case the user is prompted
+// for what to do about it. Callers should not save when this returns true.
+func guardExternalConfigChange() bool {
+	b, err := getExternalConfigChange()
+	if err != nil {
+		FP.ProfileStatusText.SetText(fmt.Sprintf("%v%v%v",
+			FP.Colors["ProfileStatusTextError"],
+			tview.Escape(err.Error()),
+			Reset,
+		))
+
+		return false
+	}
+
+	if b == nil {
+		return false
+	}
+
+	promptExternalConfigChange(b)
+
+	return true
+}
+
+// checkConfigFile is run periodically to look for external changes to the
+// config file. It waits until no other prompt is being shown.
+func checkConfigFile() {
+	p, _ := FP.Pages.GetFrontPage()
+	if p == PagePrompt {
+		return
+	}
+
+	guardExternalConfigChange()
+}
+
+// startConfigWatch periodically runs checkConfigFile in the background,
+// unless Config.DisableConfigFileWatch is set.
+func startConfigWatch() {
+	if FP.Config.DisableConfigFileWatch {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(ConfigWatchInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			FP.App.QueueUpdateDraw(checkConfigFile)
+		}
+	}()
+}
+
+// applyExternalConfig replaces the loaded config with conf, which came from
+// (or was merged with) the version of the config file on disk, whose contents
+// are b. The change is added to the undo buffer so that it can be undone.
+func applyExternalConfig(conf Config, b []byte) error {
+	saved := Config{}
+
+	err := yaml.Unmarshal(b, &saved)
+	if err != nil {
+		return fmt.Errorf("%v: %w", FP.T["ConfigFailedToUnmarshalConfig"], err)
+	}
+
+	processConfig(&saved)
+
+	name := FP.SelectedProfile.Name
+
+	FP.Config = conf
+
+	if len(FP.Config.Profiles) == 0 {
+		FP.Config.Profiles = append(FP.Config.Profiles, Profile{
+			TX:   []TX{getNewTX(time.Now())},
+			Name: FP.T["DefaultNewProfileName"],
+		})
+	}
+
+	FP.SelectedProfile = &(FP.Config.Profiles[0])
+
+	for i := range FP.Config.Profiles {
+		if FP.Config.Profiles[i].Name == name {
+			FP.SelectedProfile = &(FP.Config.Profiles[i])
+
+			break
+		}
+	}
+
+	setSavedState(&saved)
+	recordConfigFileState(b)
+
+	populateProfilesPage()
+	getTransactionsTable()
+	modified()
+
+	return nil
+}
+
+// reloadExternalConfig discards the loaded config in favor of the version on
+// disk, whose contents are b.
+func reloadExternalConfig(b []byte) error {
+	conf := Config{}
+
+	err := yaml.Unmarshal(b, &conf)
+	if err != nil {
+		return fmt.Errorf("%v: %w", FP.T["ConfigFailedToUnmarshalConfig"], err)
+	}
+
+	processConfig(&conf)
+
+	return applyExternalConfig(conf, b)
+}
+
+// mergeExternalConfig merges the loaded config with the version on disk, whose
+// contents are b, using the config as it was last loaded or saved as the
+// common base. Transactions are matched by ID.
+func mergeExternalConfig(b []byte) ([]MergeConflict, error) {
+	base := Config{}
+
+	err := yaml.Unmarshal(FP.SavedConfig, &base)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", FP.T["ConfigFailedToUnmarshalConfig"], err)
+	}
+
+	theirs := Config{}
+
+	err = yaml.Unmarshal(b, &theirs)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", FP.T["ConfigFailedToUnmarshalConfig"], err)
+	}
+
+	processConfig(&theirs)
+
+	merged, conflicts := mergeConfigs(base, FP.Config, theirs)
+
+	return conflicts, applyExternalConfig(merged, b)
+}
+
+// setExternalConfigStatus shows the outcome of handling an external change in
+// the status text.
+func setExternalConfigStatus(msg string, err error) {
+	if err != nil {
+		FP.ProfileStatusText.SetText(fmt.Sprintf("%v%v%v",
+			FP.Colors["ProfileStatusTextError"],
+			tview.Escape(err.Error()),
+			Reset,
+		))
+
+		return
+	}
+
+	FP.ProfileStatusText.SetText(fmt.Sprintf("%v%v%v",
+		FP.Colors["ProfileStatusTextPassive"],
+		msg,
+		Reset,
+	))
+}
+
+// promptExternalConfigChange warns the user that the config file was changed
+// on disk (its new contents are b), and offers to reload it, overwrite it
+// with the loaded config, merge the two, or ignore the change.
+func promptExternalConfigChange(b []byte) {
+	FP.PrevPage, _ = FP.Pages.GetFrontPage()
+	if FP.PrevPage == PagePrompt {
+		FP.PrevPage = PageProfiles
+	}
+
+	done := func() {
+		FP.Pages.SwitchToPage(FP.PrevPage)
+		setBottomPageNavText()
+	}
+
+	FP.PromptBox.ClearButtons().AddButtons(
+		[]string{
+			FP.T["PromptExternalChangeButtonReload"],
+			FP.T["PromptExternalChangeButtonOverwrite"],
+			FP.T["PromptExternalChangeButtonMerge"],
+			FP.T["PromptExternalChangeButtonIgnore"],
+		},
+	).SetText(fmt.Sprintf("%v\n\n%v", FP.T["PromptExternalChangeText"], FP.FlagConfigFile)).SetDoneFunc(
+		func(buttonIndex int, _ /* buttonLabel */ string) {
+			switch buttonIndex {
+			case 0:
+				err := reloadExternalConfig(b)
+				done()
+				setExternalConfigStatus(FP.T["ExternalChangeReloaded"], err)
+			case 1:
+				err := saveConfig(FP.FlagConfigFile, &FP.Config)
+				if err == nil {
+					markSaved()
+				}
+
+				done()
+				setExternalConfigStatus(FP.T["ExternalChangeOverwritten"], err)
+			case 2:
+				conflicts, err := mergeExternalConfig(b)
+				if err != nil || len(conflicts) == 0 {
+					done()
+					setExternalConfigStatus(FP.T["ExternalChangeMerged"], err)
+
+					return
+				}
+
+				promptMergeConflicts(conflicts)
+			default:
+				// don't ask again about this version of the file
+				recordConfigFileState(b)
+				done()
+				setExternalConfigStatus(FP.T["ExternalChangeIgnored"], nil)
+			}
+		},
+	).SetBackgroundColor(tcell.ColorGoldenrod).
+		SetTextColor(tcell.ColorBlack)
+
+	FP.Pages.SwitchToPage(PagePrompt)
+	FP.PromptBox.SetFocus(3)
+	FP.App.SetFocus(FP.PromptBox)
+}
+
+// promptMergeConflicts lists everything that conflicted while merging an
+// external change. The loaded version was kept for each of them.
+func promptMergeConflicts(conflicts []MergeConflict) {
+	lines := make([]string, len(conflicts))
+	for i := range conflicts {
+		lines[i] = conflicts[i].String()
+	}
+
+	FP.PromptBox.ClearButtons().AddButtons(
+		[]string{FP.T["PromptMergeConflictsButtonOK"]},
+	).SetText(fmt.Sprintf("%v\n\n%v",
+		FP.T["PromptMergeConflictsText"],
+		strings.Join(lines, "\n"),
+	)).SetDoneFunc(
+		func(_ int, _ /* buttonLabel */ string) {
+			FP.Pages.SwitchToPage(FP.PrevPage)
+			setBottomPageNavText()
+			setExternalConfigStatus(fmt.Sprintf("%v (%v %v)",
+				FP.T["ExternalChangeMerged"],
+				len(conflicts),
+				FP.T["ExternalChangeConflicts"],
+			), nil)
+		},
+	)
+
+	FP.PromptBox.SetFocus(0)
+	FP.App.SetFocus(FP.PromptBox)
+}